@@ -0,0 +1,215 @@
+package router
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// AccessLogConfig configures the AccessLog middleware.
+type AccessLogConfig struct {
+	// Logger to write to, slog.Default() if nil.
+	Logger *slog.Logger
+
+	// Level of the log records, slog.LevelInfo by default.
+	Level slog.Level
+
+	// Sampler decides whether a request is logged once it has been served.
+	// Every request is logged if it is nil, see RateSampler.
+	Sampler func(req *http.Request, status int) bool
+
+	// Redact lists the names of parameters whose values are replaced by
+	// "[REDACTED]", both in the params and in the raw path.
+	Redact []string
+
+	// Header carrying the request ID, "X-Request-Id" if empty.
+	RequestIDHeader string
+}
+
+// AccessLog returns a middleware which writes one structured log record per
+// request, with the method, percent-encoded path as sent, matched pattern,
+// params, status, bytes written, duration, remote address and request ID.
+// The pattern and params are only known when the wrapped handler is a Router,
+// which also reports the original method of a request it routed as another,
+// see MethodOverride.
+func AccessLog(config AccessLogConfig) func(http.Handler) http.Handler {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	header := config.RequestIDHeader
+	if header == "" {
+		header = "X-Request-Id"
+	}
+
+	redact := make(map[string]bool, len(config.Redact))
+	for _, name := range config.Redact {
+		redact[name] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			req, info := withRouteInfo(req)
+			w := &responseWriter{ResponseWriter: rw}
+			next.ServeHTTP(w, req)
+			duration := time.Since(start)
+
+			status := w.statusCode()
+			if config.Sampler != nil && !config.Sampler(req, status) {
+				return
+			}
+
+			path := req.URL.EscapedPath()
+			params := make([]any, 0, len(info.Params))
+			for name, value := range info.Params {
+				if redact[name] {
+					value = redacted
+				}
+				params = append(params, slog.String(name, value))
+			}
+			if len(redact) > 0 {
+				path = redactPath(path, info.expanded, info.Params, redact)
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", path),
+				slog.String("pattern", info.Pattern),
+				slog.Group("params", params...),
+				slog.Int("status", status),
+				slog.Int("bytes", w.size),
+				slog.Duration("duration", duration),
+				slog.String("remote_addr", req.RemoteAddr),
+				slog.String("request_id", req.Header.Get(header)),
+			}
+			if info.OriginalMethod != "" {
+				attrs[0] = slog.String("method", info.method)
+				attrs = append(attrs, slog.String("original_method", info.OriginalMethod))
+			}
+			logger.LogAttrs(req.Context(), config.Level, "request", attrs...)
+		})
+	}
+}
+
+// RateSampler returns an AccessLogConfig.Sampler which keeps a random
+// fraction rate of the requests, and every request answered with a 5xx.
+func RateSampler(rate float64) func(req *http.Request, status int) bool {
+	return func(req *http.Request, status int) bool {
+		return status >= 500 || rand.Float64() < rate
+	}
+}
+
+// redactPath replaces the segments of the percent-encoded path matched by
+// the redacted parameters of pattern. The parameter values in ps tell how
+// many decoded segments each parameter spans; an escaped segment holding an
+// encoded slash is several decoded segments, and is redacted as a whole if
+// any of them is. Consecutive segments of one wildcard become a single
+// "[REDACTED]".
+func redactPath(path, pattern string, ps Params, redact map[string]bool) string {
+	if pattern == "" {
+		return path
+	}
+
+	// owner maps each decoded segment to the escaped segment holding it
+	segments := strings.Split(path, "/")
+	var owner []int
+	for k, s := range segments {
+		n := 1
+		if u, err := url.PathUnescape(s); err == nil {
+			n += strings.Count(u, "/")
+		}
+		for ; n > 0; n-- {
+			owner = append(owner, k)
+		}
+	}
+
+	// group[k] identifies the redacted pattern segment covering the escaped
+	// segment k, 0 for none
+	group := make([]int, len(segments))
+	mixed := make(map[int]string)
+	j := 0
+	for n, frag := range strings.Split(pattern, "/") {
+		if j >= len(owner) {
+			break
+		}
+
+		span, redactFrag := 1, false
+		switch {
+		case isMixedSegment(frag):
+			parts, _ := parseSegment(frag)
+			for _, part := range parts {
+				if part.param != "" {
+					span += strings.Count(ps[part.param], "/")
+					redactFrag = redactFrag || redact[part.param]
+				}
+			}
+			// an unescaped segment keeps its literals
+			k := owner[j]
+			if redactFrag && span == 1 && strings.IndexByte(segments[k], '%') < 0 {
+				mixed[k] = redactSegment(segments[k], frag, redact)
+				redactFrag = false
+			}
+		case frag != "" && (frag[0] == ':' || frag[0] == '*'):
+			span += strings.Count(ps[frag[1:]], "/")
+			redactFrag = redact[frag[1:]]
+		}
+
+		span = min(span, len(owner)-j)
+		if redactFrag {
+			for _, k := range owner[j : j+span] {
+				group[k] = n + 1
+			}
+		}
+		j += span
+	}
+
+	out := make([]string, 0, len(segments))
+	prev := 0
+	for k, s := range segments {
+		switch g := group[k]; {
+		case g == 0:
+			if m, ok := mixed[k]; ok {
+				s = m
+			}
+			out = append(out, s)
+		case g != prev:
+			out = append(out, redacted)
+		}
+		prev = group[k]
+	}
+
+	return strings.Join(out, "/")
+}
+
+// redactSegment replaces the values of the redacted parameters in the path
+// segment s matched by the mixed pattern segment frag.
+func redactSegment(s, frag string, redact map[string]bool) string {
+	parts, _ := parseSegment(frag)
+	values, ok := matchSegment(parts, s, nil)
+	if !ok {
+		return s
+	}
+
+	var sb strings.Builder
+	i := 0
+	for _, part := range parts {
+		if part.param == "" {
+			sb.WriteString(part.literal)
+			continue
+		}
+		if redact[part.param] {
+			sb.WriteString(redacted)
+		} else {
+			sb.WriteString(values[i])
+		}
+		i++
+	}
+	return sb.String()
+}
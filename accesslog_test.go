@@ -0,0 +1,106 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Get("/users/:id/tokens/:token", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("hello"))
+	})
+
+	handler := AccessLog(AccessLogConfig{
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+		Redact: []string{"token"},
+	})(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42/tokens/secret", nil)
+	req.Header.Set("X-Request-Id", "abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "/users/42/tokens/[REDACTED]", record["path"])
+	assert.Equal(t, "/users/:id/tokens/:token", record["pattern"])
+	assert.Equal(t, map[string]any{"id": "42", "token": "[REDACTED]"}, record["params"])
+	assert.Equal(t, float64(http.StatusCreated), record["status"])
+	assert.Equal(t, float64(5), record["bytes"])
+	assert.Equal(t, "192.0.2.1:1234", record["remote_addr"])
+	assert.Equal(t, "abc", record["request_id"])
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestAccessLogEscapedPath(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Get("/files/*path", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/keys/*key", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	handler := AccessLog(AccessLogConfig{
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+		Redact: []string{"key"},
+	})(router)
+
+	for target, path := range map[string]string{
+		"/files/a%2Fb":    "/files/a%2Fb",
+		"/files/a/b":      "/files/a/b",
+		"/keys/se%2Fcret": "/keys/[REDACTED]",
+	} {
+		buf.Reset()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, path, record["path"], target)
+	}
+	assert.NotContains(t, buf.String(), "cret")
+}
+
+func TestAccessLogSampler(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Get("/ok", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/fail", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+
+	handler := AccessLog(AccessLogConfig{
+		Logger:  slog.New(slog.NewTextHandler(&buf, nil)),
+		Sampler: RateSampler(0),
+	})(router)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, 0, buf.Len())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Contains(t, buf.String(), "status=500")
+}
+
+func TestRedactPath(t *testing.T) {
+	redact := map[string]bool{"key": true}
+	assert.Equal(t, "/a/[REDACTED]/b", redactPath("/a/xyz/b", "/a/:key/b", Params{"key": "xyz"}, redact))
+	assert.Equal(t, "/a/[REDACTED]", redactPath("/a/x/y/z", "/a/*key", Params{"key": "x/y/z"}, redact))
+	assert.Equal(t, "/a/xyz", redactPath("/a/xyz", "", nil, redact))
+	assert.Equal(t, "/a/[REDACTED].json", redactPath("/a/xyz.json", "/a/:key.:ext", Params{"key": "xyz", "ext": "json"}, redact))
+	assert.Equal(t, "/a/[REDACTED]/b/c", redactPath("/a/x/y/b/c", "/a/*key/b/:id", Params{"key": "x/y", "id": "c"}, redact))
+	assert.Equal(t, "/a/x/y/b/[REDACTED]", redactPath("/a/x/y/b/c", "/a/*path/b/:key", Params{"path": "x/y", "key": "c"}, redact))
+
+	// encoded slashes split a segment for wildcards, or stay in it with
+	// UseRawPath
+	assert.Equal(t, "/a/[REDACTED]/b", redactPath("/a/x%2Fy/z/b", "/a/*key/b", Params{"key": "x/y/z"}, redact))
+	assert.Equal(t, "/a/[REDACTED]/b", redactPath("/a/x%2Fy/b", "/a/:key/b", Params{"key": "x/y"}, redact))
+	assert.Equal(t, "/a/x%2Fy/b/[REDACTED]", redactPath("/a/x%2Fy/b/c", "/a/*path/b/:key", Params{"path": "x/y", "key": "c"}, redact))
+	assert.Equal(t, "/a/[REDACTED]", redactPath("/a/x%20y.json", "/a/:key.:ext", Params{"key": "x y", "ext": "json"}, redact))
+	assert.Equal(t, "/a/[REDACTED]/[REDACTED]", redactPath("/a/x/y", "/a/:key/:id", Params{"key": "x", "id": "y"}, map[string]bool{"key": true, "id": true}))
+}
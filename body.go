@@ -0,0 +1,81 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Consumes returns a RouteOption which restricts the media types of request
+// bodies the route accepts. Requests with a body of another type are
+// answered with 415 and, for POST and PATCH, an Accept-Post or Accept-Patch
+// header listing types.
+func Consumes(types ...string) RouteOption {
+	return func(rt *route) {
+		rt.consumes = append(rt.consumes, types...)
+	}
+}
+
+// MaxBodySize returns a RouteOption which limits request bodies to n bytes.
+// Requests announcing a larger Content-Length are answered with 413; other
+// bodies are wrapped with http.MaxBytesReader, so reading past n fails with
+// a *http.MaxBytesError.
+func MaxBodySize(n int64) RouteOption {
+	return func(rt *route) {
+		rt.maxBodySize = n
+	}
+}
+
+// checkBody enforces the body restrictions of rt on req. It answers the
+// request itself and returns false if they are not met.
+func (rt *route) checkBody(rw http.ResponseWriter, req *http.Request) bool {
+	hasBody := req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+	if len(rt.consumes) > 0 && hasBody && !rt.consumesType(req.Header.Get("Content-Type")) {
+		accept := strings.Join(rt.consumes, ", ")
+		switch req.Method {
+		case http.MethodPost:
+			rw.Header().Set("Accept-Post", accept)
+		case http.MethodPatch:
+			rw.Header().Set("Accept-Patch", accept)
+		}
+		renderError(rw, req, NewProblem(http.StatusUnsupportedMediaType, "expected "+accept))
+		return false
+	}
+
+	if rt.maxBodySize > 0 && hasBody {
+		if req.ContentLength > rt.maxBodySize {
+			renderError(rw, req, NewProblem(http.StatusRequestEntityTooLarge,
+				"request body exceeds "+strconv.FormatInt(rt.maxBodySize, 10)+" bytes"))
+			return false
+		}
+		req.Body = http.MaxBytesReader(rw, req.Body, rt.maxBodySize)
+	}
+
+	return true
+}
+
+// consumesType reports whether contentType is one of the types rt consumes.
+// Types such as "text/*" match every subtype.
+func (rt *route) consumesType(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range rt.consumes {
+		if mediaTypeIncludes(t, mt) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaTypeIncludes reports whether the media range r, which may end with a
+// wildcard, includes the media type t.
+func mediaTypeIncludes(r, t string) bool {
+	if r == "*/*" || strings.EqualFold(r, t) {
+		return true
+	}
+	return strings.HasSuffix(r, "/*") && len(t) > len(r)-1 && strings.EqualFold(t[:len(r)-1], r[:len(r)-1])
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumes(t *testing.T) {
+	router := New()
+	handler := func(rw http.ResponseWriter, req *http.Request, _ Params) {}
+	router.Post("/a", handler, Consumes("application/json", "text/*"))
+	router.Patch("/a", handler, Consumes("application/merge-patch+json"))
+
+	cases := []struct {
+		method, contentType, body string
+		status                    int
+		header, value             string
+	}{
+		{http.MethodPost, "application/json; charset=utf-8", "{}", http.StatusOK, "", ""},
+		{http.MethodPost, "text/csv", "a,b", http.StatusOK, "", ""},
+		{http.MethodPost, "", "", http.StatusOK, "", ""},
+		{http.MethodPost, "application/xml", "<a/>", http.StatusUnsupportedMediaType, "Accept-Post", "application/json, text/*"},
+		{http.MethodPatch, "application/json", "{}", http.StatusUnsupportedMediaType, "Accept-Patch", "application/merge-patch+json"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, "/a", strings.NewReader(c.body))
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		assert.Equal(t, c.status, rw.Code, c.contentType)
+		if c.header != "" {
+			assert.Equal(t, c.value, rw.Header().Get(c.header))
+		}
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	router := New()
+	var readErr error
+	router.Post("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		_, readErr = io.ReadAll(req.Body)
+	}, MaxBodySize(4))
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/a", strings.NewReader("12345")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+
+	// bodies of unknown length are cut by http.MaxBytesReader
+	req := httptest.NewRequest(http.MethodPost, "/a", io.MultiReader(strings.NewReader("12345")))
+	req.ContentLength = -1
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	var maxErr *http.MaxBytesError
+	assert.True(t, errors.As(readErr, &maxErr))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/a", strings.NewReader("1234")))
+	assert.NoError(t, readErr)
+}
+
+func TestRoutes(t *testing.T) {
+	router := New()
+	handler := func(rw http.ResponseWriter, req *http.Request, _ Params) {}
+	router.Get("/b", handler, Name("b"))
+	router.Post("/a", handler, Consumes("application/json"), MaxBodySize(1<<20), Timeout(time.Second))
+	router.Get("/a", handler)
+
+	assert.Equal(t, []Route{
+		{Method: http.MethodGet, Pattern: "/a"},
+		{Method: http.MethodPost, Pattern: "/a", Consumes: []string{"application/json"}, MaxBodySize: 1 << 20, Timeout: time.Second},
+		{Method: http.MethodGet, Pattern: "/b", Name: "b"},
+	}, router.Routes())
+}
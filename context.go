@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"net/http"
+)
+
+type routeInfoKey struct{}
+
+// RouteInfo describes how the router resolved a request. It is available to
+// handlers, NoRoute and NoMethod through RouteInfoFromContext.
+type RouteInfo struct {
+	Kind LookupKind
+
+	// Pattern of the matched route including the prefix of its group, such
+	// as "/api/v1/users/:id". Empty if no route matched.
+	Pattern string
+
+	// Name given to the matched route with the Name option.
+	Name string
+
+	// Params of the matched route.
+	Params Params
+
+	// Patterns close to the request path when no route matched, see
+	// Router.MaxSuggestions.
+	Suggestions []string
+
+	// Method the client sent when Router.MethodOverride routed the request
+	// as another method, empty otherwise.
+	OriginalMethod string
+
+	router   *Router
+	resolved bool
+
+	// Pattern registered on the matched node, without optional parts.
+	expanded string
+
+	// Method the request was routed as when it was overridden.
+	method string
+}
+
+// RouteInfoFromContext returns the RouteInfo the router stored in ctx, or nil
+// if ctx doesn't come from a request served by a Router.
+func RouteInfoFromContext(ctx context.Context) *RouteInfo {
+	info, _ := ctx.Value(routeInfoKey{}).(*RouteInfo)
+	if info == nil || !info.resolved {
+		return nil
+	}
+	return info
+}
+
+// MatchedPattern returns the pattern of the route which matched req, or an
+// empty string if none did.
+func MatchedPattern(req *http.Request) string {
+	if info := RouteInfoFromContext(req.Context()); info != nil {
+		return info.Pattern
+	}
+	return ""
+}
+
+// RouteName returns the name of the route which matched req, or an empty
+// string if none did or it has no name.
+func RouteName(req *http.Request) string {
+	if info := RouteInfoFromContext(req.Context()); info != nil {
+		return info.Name
+	}
+	return ""
+}
+
+// Suggestions returns the patterns suggested for req when no route matched,
+// see Router.MaxSuggestions.
+func Suggestions(req *http.Request) []string {
+	if info := RouteInfoFromContext(req.Context()); info != nil {
+		return info.Suggestions
+	}
+	return nil
+}
+
+// NewRouteInfoContext returns a context carrying an empty RouteInfo, which
+// the Router serving a request with this context fills in. It lets
+// middleware in front of a Router see how the request was resolved.
+func NewRouteInfoContext(ctx context.Context) (context.Context, *RouteInfo) {
+	info := &RouteInfo{}
+	return context.WithValue(ctx, routeInfoKey{}, info), info
+}
+
+// withRouteInfo returns req with an unresolved RouteInfo in its context. A
+// RouteInfo installed by a middleware in front of the router is reused, so
+// that the middleware sees what the router resolved.
+func withRouteInfo(req *http.Request) (*http.Request, *RouteInfo) {
+	if info, _ := req.Context().Value(routeInfoKey{}).(*RouteInfo); info != nil && !info.resolved {
+		return req, info
+	}
+
+	ctx, info := NewRouteInfoContext(req.Context())
+	return req.WithContext(ctx), info
+}
+
+// resolve records res in info.
+func (info *RouteInfo) resolve(res LookupResult) {
+	info.Kind = res.Kind
+	info.Pattern = res.Pattern
+	info.Params = res.Params
+	info.expanded = res.expanded
+	if res.route != nil {
+		info.Name = res.route.name
+	}
+	info.resolved = true
+}
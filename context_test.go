@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteInfo(t *testing.T) {
+	router := New()
+	var info *RouteInfo
+	var pattern, name string
+	record := func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		info = RouteInfoFromContext(req.Context())
+		pattern = MatchedPattern(req)
+		name = RouteName(req)
+	}
+
+	v1 := router.Prefix("/api/v1")
+	v1.Get("/users/:id", record, Name("user"))
+	router.NoRoute = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		record(rw, req, nil)
+	})
+	router.NoMethod = router.NoRoute
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/42", nil))
+	assert.Equal(t, LookupFound, info.Kind)
+	assert.Equal(t, "/api/v1/users/:id", pattern)
+	assert.Equal(t, "user", name)
+	assert.Equal(t, Params{"id": "42"}, info.Params)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/none", nil))
+	assert.Equal(t, LookupNotFound, info.Kind)
+	assert.Equal(t, "", pattern)
+	assert.Equal(t, "", name)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/users/42", nil))
+	assert.Equal(t, LookupMethodNotAllowed, info.Kind)
+	assert.Equal(t, "", pattern)
+
+	assert.Nil(t, RouteInfoFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()))
+}
+
+func TestRouteInfoMiddleware(t *testing.T) {
+	router := New()
+	router.Get("/a/:b", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	// a middleware in front of the router sees what the router resolved
+	req, info := withRouteInfo(httptest.NewRequest(http.MethodGet, "/a/x", nil))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "/a/:b", info.Pattern)
+	assert.Equal(t, info, RouteInfoFromContext(req.Context()))
+}
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"sync"
+)
+
+// Coverage counts the requests served by each registered route, to find the
+// routes a test suite never exercises. Assign it to Router.Coverage, run the
+// tests, and inspect Report.
+type Coverage struct {
+	mu   sync.Mutex
+	hits map[*route]uint64
+}
+
+// NewCoverage returns an empty Coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{hits: make(map[*route]uint64)}
+}
+
+func (c *Coverage) record(rt *route) {
+	c.mu.Lock()
+	c.hits[rt]++
+	c.mu.Unlock()
+}
+
+// Reset forgets the requests recorded so far.
+func (c *Coverage) Reset() {
+	c.mu.Lock()
+	c.hits = make(map[*route]uint64)
+	c.mu.Unlock()
+}
+
+// Report returns the hits of every route registered on r, in the order of
+// Router.Routes.
+func (c *Coverage) Report(r *Router) *CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &CoverageReport{}
+	for _, rt := range r.routes() {
+		hits := c.hits[rt]
+		report.Routes = append(report.Routes, RouteCoverage{
+			Method:  rt.method,
+			Pattern: rt.pattern,
+			Name:    rt.name,
+			Hits:    hits,
+		})
+		report.Total++
+		if hits > 0 {
+			report.Covered++
+		}
+	}
+	return report
+}
+
+// RouteCoverage is the number of requests served by a route.
+type RouteCoverage struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+	Name    string `json:"name,omitempty"`
+	Hits    uint64 `json:"hits"`
+}
+
+// CoverageReport lists the hits of the registered routes, see
+// Coverage.Report.
+type CoverageReport struct {
+	Routes  []RouteCoverage `json:"routes"`
+	Covered int             `json:"covered"`
+	Total   int             `json:"total"`
+}
+
+// Percent returns the percentage of routes served at least once, 100 if no
+// route is registered.
+func (cr *CoverageReport) Percent() float64 {
+	if cr.Total == 0 {
+		return 100
+	}
+	return float64(cr.Covered) * 100 / float64(cr.Total)
+}
+
+// Unexercised returns the routes which served no request.
+func (cr *CoverageReport) Unexercised() []RouteCoverage {
+	var routes []RouteCoverage
+	for _, rc := range cr.Routes {
+		if rc.Hits == 0 {
+			routes = append(routes, rc)
+		}
+	}
+	return routes
+}
+
+// WriteText writes the report as a plain text table, followed by the
+// unexercised routes.
+func (cr *CoverageReport) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "route coverage: %d/%d (%.1f%%)\n", cr.Covered, cr.Total, cr.Percent()); err != nil {
+		return err
+	}
+	for _, rc := range cr.Routes {
+		if _, err := fmt.Fprintf(w, "%8d  %-7s %s\n", rc.Hits, rc.Method, rc.Pattern); err != nil {
+			return err
+		}
+	}
+
+	unexercised := cr.Unexercised()
+	if len(unexercised) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "unexercised:"); err != nil {
+		return err
+	}
+	for _, rc := range unexercised {
+		if _, err := fmt.Fprintf(w, "  %s %s\n", rc.Method, rc.Pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the report as JSON, with its percentage and unexercised
+// routes.
+func (cr *CoverageReport) WriteJSON(w io.Writer) error {
+	unexercised := cr.Unexercised()
+	if unexercised == nil {
+		unexercised = []RouteCoverage{}
+	}
+
+	return json.NewEncoder(w).Encode(struct {
+		*CoverageReport
+		Percent     float64         `json:"percent"`
+		Unexercised []RouteCoverage `json:"unexercised"`
+	}{cr, cr.Percent(), unexercised})
+}
+
+// WriteHTML writes the report as an HTML page holding a table of the routes,
+// unexercised ones highlighted.
+func (cr *CoverageReport) WriteHTML(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Route coverage</title>
+<style>table{border-collapse:collapse}td,th{padding:2px 8px;text-align:left}tr.miss{background:#fdd}</style>
+</head><body>
+<h1>Route coverage: %d/%d (%.1f%%)</h1>
+<table>
+<tr><th>Method</th><th>Pattern</th><th>Name</th><th>Hits</th></tr>
+`, cr.Covered, cr.Total, cr.Percent()); err != nil {
+		return err
+	}
+
+	for _, rc := range cr.Routes {
+		class := ""
+		if rc.Hits == 0 {
+			class = ` class="miss"`
+		}
+		if _, err := fmt.Fprintf(w, "<tr%s><td>%s</td><td>%s</td><td>%s</td><td>%d</td></tr>\n",
+			class, html.EscapeString(rc.Method), html.EscapeString(rc.Pattern), html.EscapeString(rc.Name), rc.Hits); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "</table>\n</body></html>\n")
+	return err
+}
@@ -0,0 +1,72 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverage(t *testing.T) {
+	router := New()
+	router.Coverage = NewCoverage()
+	h := func(rw http.ResponseWriter, req *http.Request, _ Params) {}
+	router.Get("/users/:id", h, Name("getUser"))
+	router.Post("/users", h)
+	router.Delete("/users/:id", h)
+	router.Get("/<script>", h)
+
+	for _, target := range []string{"/users/1", "/users/2", "/none"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	report := router.Coverage.Report(router)
+	assert.Equal(t, 2, report.Covered)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 50.0, report.Percent())
+	assert.Equal(t, []RouteCoverage{
+		{Method: http.MethodGet, Pattern: "/<script>"},
+		{Method: http.MethodPost, Pattern: "/users", Hits: 1},
+		{Method: http.MethodDelete, Pattern: "/users/:id"},
+		{Method: http.MethodGet, Pattern: "/users/:id", Name: "getUser", Hits: 2},
+	}, report.Routes)
+	assert.Equal(t, []RouteCoverage{
+		{Method: http.MethodGet, Pattern: "/<script>"},
+		{Method: http.MethodDelete, Pattern: "/users/:id"},
+	}, report.Unexercised())
+
+	var sb strings.Builder
+	assert.Nil(t, report.WriteText(&sb))
+	assert.Contains(t, sb.String(), "route coverage: 2/4 (50.0%)\n")
+	assert.Contains(t, sb.String(), "       2  GET     /users/:id\n")
+	assert.Contains(t, sb.String(), "unexercised:\n  GET /<script>\n  DELETE /users/:id\n")
+
+	sb.Reset()
+	assert.Nil(t, report.WriteJSON(&sb))
+	var doc struct {
+		Covered     int
+		Total       int
+		Percent     float64
+		Routes      []RouteCoverage
+		Unexercised []RouteCoverage
+	}
+	assert.Nil(t, json.Unmarshal([]byte(sb.String()), &doc))
+	assert.Equal(t, 2, doc.Covered)
+	assert.Equal(t, 50.0, doc.Percent)
+	assert.Len(t, doc.Routes, 4)
+	assert.Equal(t, report.Unexercised(), doc.Unexercised)
+
+	sb.Reset()
+	assert.Nil(t, report.WriteHTML(&sb))
+	assert.Contains(t, sb.String(), `<tr class="miss"><td>GET</td><td>/&lt;script&gt;</td><td></td><td>0</td></tr>`)
+	assert.Contains(t, sb.String(), `<tr><td>GET</td><td>/users/:id</td><td>getUser</td><td>2</td></tr>`)
+
+	router.Coverage.Reset()
+	report = router.Coverage.Report(router)
+	assert.Equal(t, 0, report.Covered)
+	assert.Equal(t, 100.0, NewCoverage().Report(New()).Percent())
+}
@@ -0,0 +1,296 @@
+package router
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limiter admits or rejects requests before a route's handler runs, see
+// Limit.
+type Limiter interface {
+	// Acquire admits req and returns a function releasing what it holds, or
+	// returns a *LimitError rejecting it. It may block until ctx of req is
+	// done.
+	Acquire(req *http.Request) (release func(), err error)
+}
+
+// LimitError is returned by a Limiter which rejects a request.
+type LimitError struct {
+	// Status code of the response, such as 429 or 503.
+	Status int
+
+	// Delay after which the client may retry, sent as Retry-After when not
+	// zero.
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("router: request rejected with %d, retry after %s", e.Status, e.RetryAfter)
+}
+
+// Limit returns a RouteOption which makes requests go through limiters, in
+// order, before the route's handler runs. A limiter given to several routes,
+// or to a group with Prefix, is shared by all of them.
+func Limit(limiters ...Limiter) RouteOption {
+	return func(rt *route) {
+		rt.limiters = append(rt.limiters, limiters...)
+	}
+}
+
+// acquire admits req through the limiters of rt. It answers the request
+// itself and returns false if one rejects it.
+func (rt *route) acquire(rw http.ResponseWriter, req *http.Request) (release func(), ok bool) {
+	releases := make([]func(), 0, len(rt.limiters))
+	release = func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	for _, l := range rt.limiters {
+		rel, err := l.Acquire(req)
+		if err != nil {
+			release()
+
+			p := NewProblem(http.StatusServiceUnavailable, err.Error())
+			if le, ok := err.(*LimitError); ok {
+				p = NewProblem(le.Status, "")
+				if le.RetryAfter > 0 {
+					rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(le.RetryAfter.Seconds()))))
+				}
+			}
+			renderError(rw, req, p)
+			return nil, false
+		}
+		releases = append(releases, rel)
+	}
+
+	return release, true
+}
+
+// Bulkhead is a Limiter which bounds the number of requests served
+// concurrently, optionally queueing the ones above the bound.
+type Bulkhead struct {
+	// Sent as Retry-After with the 503 answered to rejected requests.
+	RetryAfter time.Duration
+
+	sem          chan struct{}
+	maxQueue     int
+	queueTimeout time.Duration
+
+	mu       sync.Mutex
+	queued   int
+	rejected uint64
+}
+
+// BulkheadStats is a snapshot of the state of a Bulkhead.
+type BulkheadStats struct {
+	InFlight int
+	Queued   int
+	Rejected uint64
+}
+
+// NewBulkhead returns a Bulkhead serving up to maxInFlight requests at once.
+// Up to maxQueue more requests wait for a slot, for at most queueTimeout if it
+// is not zero. Other requests are rejected with 503.
+func NewBulkhead(maxInFlight, maxQueue int, queueTimeout time.Duration) *Bulkhead {
+	if maxInFlight <= 0 {
+		panic("bulkhead must allow at least one request in flight")
+	}
+
+	return &Bulkhead{
+		RetryAfter:   time.Second,
+		sem:          make(chan struct{}, maxInFlight),
+		maxQueue:     maxQueue,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Acquire implements Limiter.
+func (b *Bulkhead) Acquire(req *http.Request) (func(), error) {
+	select {
+	case b.sem <- struct{}{}:
+		return b.release, nil
+	default:
+	}
+
+	b.mu.Lock()
+	if b.queued >= b.maxQueue {
+		b.rejected++
+		b.mu.Unlock()
+		return nil, b.reject()
+	}
+	b.queued++
+	b.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if b.queueTimeout > 0 {
+		timer := time.NewTimer(b.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var admitted bool
+	select {
+	case b.sem <- struct{}{}:
+		admitted = true
+	case <-timeout:
+	case <-req.Context().Done():
+	}
+
+	b.mu.Lock()
+	b.queued--
+	if !admitted {
+		b.rejected++
+	}
+	b.mu.Unlock()
+
+	if !admitted {
+		return nil, b.reject()
+	}
+	return b.release, nil
+}
+
+func (b *Bulkhead) release() {
+	<-b.sem
+}
+
+func (b *Bulkhead) reject() error {
+	return &LimitError{Status: http.StatusServiceUnavailable, RetryAfter: b.RetryAfter}
+}
+
+// Stats returns the current state of b.
+func (b *Bulkhead) Stats() BulkheadStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BulkheadStats{
+		InFlight: len(b.sem),
+		Queued:   b.queued,
+		Rejected: b.rejected,
+	}
+}
+
+// KeyFunc returns the key a RateLimiter accounts a request to.
+type KeyFunc func(req *http.Request) string
+
+// KeyByClientIP is a KeyFunc returning the IP address of the client, taken
+// from req.RemoteAddr. Forwarding headers are not trusted.
+func KeyByClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// KeyByHeader returns a KeyFunc returning the value of the header name.
+func KeyByHeader(name string) KeyFunc {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// RateLimiter is a Limiter which admits requests through a token bucket per
+// key, and rejects the others with 429.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	key   KeyFunc
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	allowed   uint64
+	rejected  uint64
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiterStats is a snapshot of the state of a RateLimiter.
+type RateLimiterStats struct {
+	Keys     int
+	Allowed  uint64
+	Rejected uint64
+}
+
+// NewRateLimiter returns a RateLimiter admitting, for each key returned by
+// key, rate requests per second on average and bursts of up to burst
+// requests. Requests are keyed by client IP if key is nil.
+func NewRateLimiter(rate float64, burst int, key KeyFunc) *RateLimiter {
+	if rate <= 0 || burst <= 0 {
+		panic("rate limiter must have a positive rate and burst")
+	}
+	if key == nil {
+		key = KeyByClientIP
+	}
+
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		key:     key,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Acquire implements Limiter.
+func (l *RateLimiter) Acquire(req *http.Request) (func(), error) {
+	key := l.key(req)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		l.rejected++
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return nil, &LimitError{Status: http.StatusTooManyRequests, RetryAfter: wait}
+	}
+
+	b.tokens--
+	l.allowed++
+	return func() {}, nil
+}
+
+// sweep forgets the buckets which have been idle long enough to be full
+// again.
+func (l *RateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Stats returns the current state of l.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return RateLimiterStats{
+		Keys:     len(l.buckets),
+		Allowed:  l.allowed,
+		Rejected: l.rejected,
+	}
+}
@@ -0,0 +1,122 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkhead(t *testing.T) {
+	bulkhead := NewBulkhead(1, 1, 0)
+	router := New()
+	started, unblock := make(chan struct{}), make(chan struct{})
+	api := router.Prefix("/api", Limit(bulkhead))
+	api.Get("/block", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		started <- struct{}{}
+		<-unblock
+	})
+	api.Get("/other", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/block", nil))
+	}()
+	<-started
+
+	// the group shares one bulkhead, the second request waits in the queue
+	queued := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		router.ServeHTTP(queued, httptest.NewRequest(http.MethodGet, "/api/other", nil))
+	}()
+	for bulkhead.Stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/other", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+	assert.Equal(t, "1", rw.Header().Get("Retry-After"))
+	assert.Equal(t, BulkheadStats{InFlight: 1, Queued: 1, Rejected: 1}, bulkhead.Stats())
+
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, queued.Code)
+	assert.Equal(t, BulkheadStats{Rejected: 1}, bulkhead.Stats())
+}
+
+func TestBulkheadTimeout(t *testing.T) {
+	bulkhead := NewBulkhead(1, 0, 0)
+	router := New()
+	router.TimeoutStatus = http.StatusGatewayTimeout
+	unblock, done := make(chan struct{}), make(chan struct{})
+	router.Get("/slow", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		<-unblock
+		close(done)
+	}, Limit(bulkhead), Timeout(10*time.Millisecond))
+
+	serve := func() int {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		return rw.Code
+	}
+
+	// the handler keeps its slot after timing out, until it returns
+	assert.Equal(t, http.StatusGatewayTimeout, serve())
+	assert.Equal(t, 1, bulkhead.Stats().InFlight)
+	assert.Equal(t, http.StatusServiceUnavailable, serve())
+
+	close(unblock)
+	<-done
+	for bulkhead.Stats().InFlight != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBulkheadQueueTimeout(t *testing.T) {
+	bulkhead := NewBulkhead(1, 1, 10*time.Millisecond)
+	release, err := bulkhead.Acquire(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NoError(t, err)
+
+	_, err = bulkhead.Acquire(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, &LimitError{Status: http.StatusServiceUnavailable, RetryAfter: time.Second}, err)
+	release()
+	assert.Equal(t, BulkheadStats{Rejected: 1}, bulkhead.Stats())
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(1, 2, KeyByHeader("X-Api-Key"))
+	router := New()
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Limit(limiter))
+
+	serve := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/a", nil)
+		req.Header.Set("X-Api-Key", key)
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		return rw
+	}
+
+	assert.Equal(t, http.StatusOK, serve("a").Code)
+	assert.Equal(t, http.StatusOK, serve("a").Code)
+	rw := serve("a")
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, "1", rw.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("b").Code)
+	assert.Equal(t, RateLimiterStats{Keys: 2, Allowed: 3, Rejected: 1}, limiter.Stats())
+}
+
+func TestKeyByClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	assert.Equal(t, "203.0.113.7", KeyByClientIP(req))
+	req.RemoteAddr = "pipe"
+	assert.Equal(t, "pipe", KeyByClientIP(req))
+}
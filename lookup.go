@@ -0,0 +1,252 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// LookupKind describes how the router resolves a request.
+type LookupKind int
+
+const (
+	// LookupNotFound means no route matches, NoRoute handles the request.
+	LookupNotFound LookupKind = iota
+
+	// LookupFound means a route matches and its handler serves the request.
+	LookupFound
+
+	// LookupRedirect means the request is redirected to Location because
+	// the path only matches with (without) a trailing slash.
+	LookupRedirect
+
+	// LookupMethodNotAllowed means the method is not allowed, NoMethod
+	// handles the request.
+	LookupMethodNotAllowed
+
+	// LookupNotAcceptable means routes match the method and path, but all of
+	// them were rejected by an Accept matcher or none produces a media type
+	// the request accepts.
+	LookupNotAcceptable
+
+	// LookupBadRequest means the path is malformed, such as an invalid
+	// percent-encoding with Router.UseRawPath, or rejected by the limits and
+	// checks of Router.MaxPathLength, MaxSegments, MaxParamLength or
+	// StrictPaths.
+	LookupBadRequest
+)
+
+func (k LookupKind) String() string {
+	switch k {
+	case LookupFound:
+		return "found"
+	case LookupRedirect:
+		return "redirect"
+	case LookupMethodNotAllowed:
+		return "method not allowed"
+	case LookupNotAcceptable:
+		return "not acceptable"
+	case LookupBadRequest:
+		return "bad request"
+	default:
+		return "not found"
+	}
+}
+
+// LookupResult is the outcome of resolving a request against the routes.
+type LookupResult struct {
+	Kind LookupKind
+
+	// Handle and Params of the matched route, set for LookupFound.
+	Handle Handle
+	Params Params
+
+	// Pattern of the matched route, set for LookupFound.
+	Pattern string
+
+	// Target of the redirect, set for LookupRedirect.
+	Location string
+
+	// Media type negotiated with Accept for LookupFound, when the route
+	// declares Produces.
+	MediaType string
+
+	// Media types produced by the routes for the method and path, set when
+	// the route was chosen by content negotiation or none was acceptable.
+	Available []string
+
+	// Methods registered for the path, or for any path if the path matches
+	// no route. Set for LookupMethodNotAllowed.
+	Allowed []string
+
+	// API version requested, see Router.Versioning. Set for LookupFound.
+	Version string
+
+	route   *route
+	version *Version
+
+	// Pattern registered on the matched node, see route.expansion.
+	expanded string
+
+	// Status code and detail of the error answered for LookupBadRequest.
+	status int
+	detail string
+}
+
+// Lookup resolves method and path the way ServeHTTP would, without calling
+// any handler. Routes guarded by matchers are tried against a request which
+// has no headers. With UseRawPath, path is percent-encoded.
+func (r *Router) Lookup(method, path string) LookupResult {
+	u := &url.URL{Path: path}
+	if r.UseRawPath {
+		u.Path, _ = url.PathUnescape(path)
+		u.RawPath = path
+	}
+
+	return r.lookup(&http.Request{
+		Method: method,
+		URL:    u,
+		Header: make(http.Header),
+	})
+}
+
+func (r *Router) lookup(req *http.Request) LookupResult {
+	path := req.URL.Path
+	if r.UseRawPath || r.StrictPaths || r.MaxPathLength > 0 || r.MaxSegments > 0 {
+		// RawPath is only kept when it is a valid encoding of Path, look at
+		// it first to reject malformed escapes.
+		raw := req.URL.RawPath
+		if raw == "" {
+			raw = req.URL.EscapedPath()
+		}
+		if r.UseRawPath {
+			path = raw
+		}
+		// without UseRawPath an encoded slash splits segments too
+		if status, detail := r.checkPath(raw, path); status != 0 {
+			return LookupResult{Kind: LookupBadRequest, status: status, detail: detail}
+		}
+	}
+	if path == "" || path[0] != '/' {
+		return LookupResult{Kind: LookupNotFound}
+	}
+
+	for _, vs := range r.versionings {
+		name, version, paths := vs.resolve(req, path)
+		for _, p := range paths {
+			if res := r.lookupPath(req, p); res.Kind == LookupFound {
+				res.Version = name
+				res.version = version
+				return res
+			}
+		}
+	}
+
+	return r.lookupPath(req, path)
+}
+
+// lookupPath resolves req as if its path was path.
+func (r *Router) lookupPath(req *http.Request, path string) LookupResult {
+	pattern := path
+	if r.IgnoreCase {
+		pattern = strings.ToLower(pattern)
+	}
+
+	var n *node
+	var ps Params
+	var tsr bool
+	if r.UseRawPath {
+		frags, err := unescapeSegments(pattern)
+		if err != nil {
+			return LookupResult{Kind: LookupBadRequest, status: http.StatusBadRequest, detail: "malformed path"}
+		}
+		n, ps, tsr = r.tree.match(frags, nil)
+	} else {
+		n, ps, tsr = r.tree.find(pattern)
+	}
+
+	if n != nil {
+		sel := selectMethod(n, req)
+		if sel.route != nil {
+			if !r.checkParams(ps) {
+				return LookupResult{Kind: LookupBadRequest, status: http.StatusBadRequest, detail: "parameter too long"}
+			}
+			return LookupResult{
+				Kind:      LookupFound,
+				Handle:    sel.route.handler,
+				Params:    ps,
+				Pattern:   sel.route.pattern,
+				MediaType: sel.mediaType,
+				Available: sel.available,
+				route:     sel.route,
+				expanded:  sel.route.expansion(n.pattern),
+			}
+		}
+
+		if sel.status == http.StatusNotAcceptable {
+			return LookupResult{Kind: LookupNotAcceptable, Available: sel.available}
+		}
+	} else if r.TrailingSlashRedirect && tsr {
+		// TrailingSlashRedirect: /a/b/ -> /a/b
+		// TrailingSlashRedirect: /a/b -> /a/b/
+		location := path
+		if len(path) > 1 && path[len(path)-1] == '/' {
+			location = path[:len(path)-1]
+		} else if len(path) > 1 {
+			location = path + "/"
+		}
+		return LookupResult{Kind: LookupRedirect, Location: location}
+	}
+
+	if !r.allowMethods[req.Method] {
+		return LookupResult{Kind: LookupMethodNotAllowed, Allowed: r.allowed(n)}
+	}
+
+	return LookupResult{Kind: LookupNotFound}
+}
+
+// unescapeSegments splits the percent-encoded path into segments and
+// unescapes each of them, so that an encoded slash stays inside its segment.
+func unescapeSegments(path string) ([]string, error) {
+	frags := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, frag := range frags {
+		if strings.IndexByte(frag, '%') < 0 {
+			continue
+		}
+		s, err := url.PathUnescape(frag)
+		if err != nil {
+			return nil, err
+		}
+		frags[i] = s
+	}
+	return frags, nil
+}
+
+// allowed returns the sorted methods registered on n, or on the router if n
+// is nil, extension methods included, and HEAD if a GET route serves it. Any
+// routes are left out since they never cause a 405.
+func (r *Router) allowed(n *node) []string {
+	var methods []string
+	if n != nil {
+		for method, routes := range n.handlers {
+			if len(routes) > 0 && method != anyMethod {
+				methods = append(methods, method)
+			}
+		}
+		if len(n.handlers[http.MethodHead]) == 0 && len(headRoutes(n)) > 0 {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+
+	if len(methods) == 0 {
+		for method := range r.allowMethods {
+			if method != anyMethod {
+				methods = append(methods, method)
+			}
+		}
+	}
+
+	sort.Strings(methods)
+	return methods
+}
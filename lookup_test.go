@@ -0,0 +1,104 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	router := New()
+	router.IgnoreCase = true
+	router.Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Put("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/a/b/", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/json", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Match(Accept("application/json")))
+
+	res := router.Lookup(http.MethodGet, "/Users/42")
+	assert.Equal(t, LookupFound, res.Kind)
+	assert.NotNil(t, res.Handle)
+	assert.Equal(t, "/users/:id", res.Pattern)
+	assert.Equal(t, Params{"id": "42"}, res.Params)
+
+	res = router.Lookup(http.MethodGet, "/a/b")
+	assert.Equal(t, LookupRedirect, res.Kind)
+	assert.Equal(t, "/a/b/", res.Location)
+
+	res = router.Lookup(http.MethodDelete, "/users/42")
+	assert.Equal(t, LookupMethodNotAllowed, res.Kind)
+	assert.Equal(t, []string{http.MethodGet, http.MethodPut}, res.Allowed)
+
+	res = router.Lookup(http.MethodGet, "/none")
+	assert.Equal(t, LookupNotFound, res.Kind)
+	assert.Nil(t, res.Handle)
+
+	res = router.Lookup(http.MethodGet, "")
+	assert.Equal(t, LookupNotFound, res.Kind)
+
+	router.TrailingSlashRedirect = false
+	res = router.Lookup(http.MethodGet, "/a/b")
+	assert.Equal(t, LookupNotFound, res.Kind)
+
+	// a request without Accept header admits everything
+	res = router.Lookup(http.MethodGet, "/json")
+	assert.Equal(t, LookupFound, res.Kind)
+	assert.Equal(t, "found", res.Kind.String())
+}
+
+func TestAllowHeader(t *testing.T) {
+	router := New()
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Post("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodPatch, "/a", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	assert.Equal(t, "GET, POST", rw.Header().Get("Allow"))
+}
+
+func TestUseRawPath(t *testing.T) {
+	router := New()
+	var params Params
+	handler := func(rw http.ResponseWriter, req *http.Request, ps Params) {
+		params = ps
+	}
+	router.Get("/files/:name", handler)
+	router.Get("/files/:name/:file", handler)
+	router.Get("/static/*path", handler)
+	router.Get("/a b/:id", handler)
+
+	serve := func(target string) int {
+		params = nil
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, target, nil))
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/files/a%2Fb"))
+	assert.Equal(t, Params{"name": "a", "file": "b"}, params)
+
+	router.UseRawPath = true
+	assert.Equal(t, http.StatusOK, serve("/files/a%2Fb"))
+	assert.Equal(t, Params{"name": "a/b"}, params)
+	assert.Equal(t, http.StatusOK, serve("/files/a%252Fb"))
+	assert.Equal(t, Params{"name": "a%2Fb"}, params)
+	assert.Equal(t, http.StatusOK, serve("/files/x/a%2Fb"))
+	assert.Equal(t, Params{"name": "x", "file": "a/b"}, params)
+	assert.Equal(t, http.StatusOK, serve("/a%20b/%C3%A9"))
+	assert.Equal(t, Params{"id": "é"}, params)
+	assert.Equal(t, http.StatusOK, serve("/static/x/y%2Fz"))
+	assert.Equal(t, Params{"path": "x/y/z"}, params)
+
+	req := httptest.NewRequest(http.MethodGet, "/files/x", nil)
+	req.URL.RawPath = "/files/%zz"
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+
+	res := router.Lookup(http.MethodGet, "/files/a%2Fb")
+	assert.Equal(t, LookupFound, res.Kind)
+	assert.Equal(t, Params{"name": "a/b"}, res.Params)
+	assert.Equal(t, LookupBadRequest, router.Lookup(http.MethodGet, "/files/%2").Kind)
+}
@@ -0,0 +1,191 @@
+package router
+
+import (
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Matcher is an additional condition, beyond method and path, that a request
+// must satisfy for a route to handle it.
+type Matcher interface {
+	Match(req *http.Request) bool
+}
+
+// MatcherFunc is an adapter to allow the use of ordinary functions as
+// Matchers.
+type MatcherFunc func(req *http.Request) bool
+
+// Match calls f(req).
+func (f MatcherFunc) Match(req *http.Request) bool {
+	return f(req)
+}
+
+// Header matches requests carrying the header key. If value is not empty the
+// header must also be equal to it.
+func Header(key, value string) Matcher {
+	return MatcherFunc(func(req *http.Request) bool {
+		values, ok := req.Header[http.CanonicalHeaderKey(key)]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Query matches requests carrying the query parameter key. If value is not
+// empty the parameter must also be equal to it.
+func Query(key, value string) Matcher {
+	return MatcherFunc(func(req *http.Request) bool {
+		values, ok := req.URL.Query()[key]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Cookie matches requests carrying the cookie name. If value is not empty the
+// cookie must also be equal to it.
+func Cookie(name, value string) Matcher {
+	return MatcherFunc(func(req *http.Request) bool {
+		c, err := req.Cookie(name)
+		if err != nil {
+			return false
+		}
+		return value == "" || c.Value == value
+	})
+}
+
+// Scheme matches requests made over one of schemes, such as "http" or
+// "https". X-Forwarded-Proto is not consulted.
+func Scheme(schemes ...string) Matcher {
+	return MatcherFunc(func(req *http.Request) bool {
+		scheme := "http"
+		if req.TLS != nil {
+			scheme = "https"
+		}
+		for _, s := range schemes {
+			if strings.EqualFold(s, scheme) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Host matches requests for one of hosts, compared case-insensitively with
+// the Host header. A host without a port matches the header on any port.
+func Host(hosts ...string) Matcher {
+	return MatcherFunc(func(req *http.Request) bool {
+		for _, h := range hosts {
+			if strings.EqualFold(h, req.Host) {
+				return true
+			}
+			if strings.Contains(h, ":") {
+				continue
+			}
+			if host, _, err := net.SplitHostPort(req.Host); err == nil && strings.EqualFold(h, host) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ContentType matches requests whose Content-Type media type is one of types.
+// Media type parameters such as charset are ignored.
+func ContentType(types ...string) Matcher {
+	return MatcherFunc(func(req *http.Request) bool {
+		mt, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil {
+			return false
+		}
+		for _, t := range types {
+			if strings.EqualFold(t, mt) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Accept matches requests whose Accept header admits one of types. A request
+// without an Accept header admits everything. When every route for a path is
+// rejected by an Accept matcher the router answers 406.
+func Accept(types ...string) Matcher {
+	return acceptMatcher(types)
+}
+
+type acceptMatcher []string
+
+func (m acceptMatcher) Match(req *http.Request) bool {
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return true
+	}
+
+	for _, t := range m {
+		if acceptQuality(accept, t) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (m acceptMatcher) mismatchStatus() int {
+	return http.StatusNotAcceptable
+}
+
+// acceptQuality returns the q-value the Accept header accept gives to the
+// media type t, taken from the most specific media range including it. It
+// returns 0 when no range includes t.
+func acceptQuality(accept, t string) float64 {
+	q, specificity := 0.0, -1
+	for _, r := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(r))
+		if err != nil {
+			continue
+		}
+
+		s := -1
+		switch {
+		case strings.EqualFold(mt, t):
+			s = 2
+		case strings.HasSuffix(mt, "/*") && mt != "*/*" && strings.HasPrefix(strings.ToLower(t), mt[:len(mt)-1]):
+			s = 1
+		case mt == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+
+	return q
+}
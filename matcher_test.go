@@ -0,0 +1,108 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	router := New()
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("v2"))
+	}, Match(Accept("application/vnd.v2+json")))
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("query"))
+	}, Match(Query("debug", "1")))
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("default"))
+	})
+	router.Get("/b", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("default"))
+	})
+	router.Get("/b", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("query"))
+	}, Match(Query("debug", "1")))
+
+	assert.Panics(t, func() {
+		router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	})
+
+	cases := []struct {
+		target string
+		header string
+		body   string
+	}{
+		{"/a", "application/vnd.v2+json", "v2"},
+		{"/a?debug=1", "text/html", "query"},
+		{"/a", "text/html", "default"},
+		{"/a", "", "v2"},
+		{"/b?debug=1", "", "query"},
+		{"/b", "", "default"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.target, nil)
+		if c.header != "" {
+			req.Header.Set("Accept", c.header)
+		}
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, c.body, rw.Body.String())
+	}
+}
+
+func TestMatcherMismatch(t *testing.T) {
+	router := New()
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Match(Accept("application/json")))
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Match(Accept("text/html")))
+	router.Get("/b", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Match(Header("X-Token", "")))
+	router.Get("/b", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Match(Accept("text/html")))
+
+	req := httptest.NewRequest(http.MethodGet, "/a", nil)
+	req.Header.Set("Accept", "image/png")
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusNotAcceptable, rw.Code)
+	assert.Equal(t, default406Body, rw.Body.Bytes())
+
+	req = httptest.NewRequest(http.MethodGet, "/b", nil)
+	req.Header.Set("Accept", "image/png")
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestBuiltinMatchers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/a?x=1", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Version", "2")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+	assert.True(t, Header("x-version", "2").Match(req))
+	assert.True(t, Header("X-Version", "").Match(req))
+	assert.False(t, Header("X-Version", "1").Match(req))
+	assert.True(t, Query("x", "1").Match(req))
+	assert.False(t, Query("y", "").Match(req))
+	assert.True(t, Cookie("session", "abc").Match(req))
+	assert.False(t, Cookie("session", "xyz").Match(req))
+	assert.True(t, Scheme("https").Match(req))
+	assert.False(t, Scheme("http").Match(req))
+	assert.True(t, Host("other.com", "Example.com").Match(req))
+	assert.False(t, Host("example.com:8080").Match(req))
+	req.Host = "example.com:8080"
+	assert.True(t, Host("example.com").Match(req))
+	assert.True(t, Host("example.com:8080").Match(req))
+	assert.True(t, ContentType("application/json").Match(req))
+	assert.False(t, ContentType("text/plain").Match(req))
+}
+
+func TestAcceptQuality(t *testing.T) {
+	assert.Equal(t, 1.0, acceptQuality("application/json", "application/json"))
+	assert.Equal(t, 0.5, acceptQuality("text/*;q=0.5, */*;q=0.1", "text/html"))
+	assert.Equal(t, 0.1, acceptQuality("text/*;q=0.5, */*;q=0.1", "image/png"))
+	assert.Equal(t, 0.0, acceptQuality("text/html;q=0, */*", "text/html"))
+	assert.Equal(t, 0.0, acceptQuality("text/html", "application/json"))
+}
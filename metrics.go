@@ -0,0 +1,192 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram buckets, in seconds, used by
+// NewMetrics when none are given.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics records request counts, in-flight requests and latency histograms
+// labelled by method, matched pattern, status code and outcome, and renders
+// them in the Prometheus text exposition format. Assign it to Router.Metrics
+// and serve it on a route of your choice.
+//
+// Requests which match no route are recorded with an empty route label and
+// an outcome of "redirect", "method_not_allowed", "not_acceptable" or
+// "not_found". Methods which are registered on no route are recorded as
+// "OTHER".
+type Metrics struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	series   map[seriesKey]*series
+	inflight map[inflightKey]int64
+}
+
+type seriesKey struct {
+	method, route, code, outcome string
+}
+
+type inflightKey struct {
+	method, route string
+}
+
+type series struct {
+	count  uint64
+	sum    float64
+	counts []uint64
+}
+
+// NewMetrics returns an empty Metrics using buckets as the upper bounds, in
+// seconds, of its latency histograms, or DefaultBuckets if none are given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Metrics{
+		buckets:  b,
+		series:   make(map[seriesKey]*series),
+		inflight: make(map[inflightKey]int64),
+	}
+}
+
+func (m *Metrics) begin(method string, info *RouteInfo) {
+	m.mu.Lock()
+	m.inflight[inflightKey{method, info.Pattern}]++
+	m.mu.Unlock()
+}
+
+func (m *Metrics) end(method string, info *RouteInfo, w *responseWriter, d time.Duration) {
+	status := w.statusCode()
+	key := seriesKey{method, info.Pattern, strconv.Itoa(status), outcome(info.Kind)}
+	seconds := d.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if info.resolved {
+		m.inflight[inflightKey{method, info.Pattern}]--
+	}
+
+	s := m.series[key]
+	if s == nil {
+		s = &series{counts: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+	s.count++
+	s.sum += seconds
+	for i, b := range m.buckets {
+		if seconds <= b {
+			s.counts[i]++
+		}
+	}
+}
+
+func outcome(kind LookupKind) string {
+	return strings.ReplaceAll(kind.String(), " ", "_")
+}
+
+func (r *Router) metricsMethod(method string) string {
+	if r.allowMethods[method] {
+		return method
+	}
+	return "OTHER"
+}
+
+// ServeHTTP renders the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(rw)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	keys := make([]seriesKey, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.code != b.code {
+			return a.code < b.code
+		}
+		return a.outcome < b.outcome
+	})
+
+	flights := make([]inflightKey, 0, len(m.inflight))
+	for k := range m.inflight {
+		flights = append(flights, k)
+	}
+	sort.Slice(flights, func(i, j int) bool {
+		if flights[i].route != flights[j].route {
+			return flights[i].route < flights[j].route
+		}
+		return flights[i].method < flights[j].method
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# HELP router_requests_total Total number of requests served by the router.\n")
+	sb.WriteString("# TYPE router_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "router_requests_total{%s} %d\n", k.labels(), m.series[k].count)
+	}
+
+	sb.WriteString("# HELP router_requests_in_flight Number of requests currently being served.\n")
+	sb.WriteString("# TYPE router_requests_in_flight gauge\n")
+	for _, k := range flights {
+		fmt.Fprintf(&sb, "router_requests_in_flight{method=%s,route=%s} %d\n",
+			quoteLabel(k.method), quoteLabel(k.route), m.inflight[k])
+	}
+
+	sb.WriteString("# HELP router_request_duration_seconds Latency of requests served by the router.\n")
+	sb.WriteString("# TYPE router_request_duration_seconds histogram\n")
+	for _, k := range keys {
+		s, labels := m.series[k], k.labels()
+		for i, b := range m.buckets {
+			fmt.Fprintf(&sb, "router_request_duration_seconds_bucket{%s,le=%s} %d\n",
+				labels, quoteLabel(formatFloat(b)), s.counts[i])
+		}
+		fmt.Fprintf(&sb, "router_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count)
+		fmt.Fprintf(&sb, "router_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(s.sum))
+		fmt.Fprintf(&sb, "router_request_duration_seconds_count{%s} %d\n", labels, s.count)
+	}
+	m.mu.Unlock()
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func (k seriesKey) labels() string {
+	return "method=" + quoteLabel(k.method) +
+		",route=" + quoteLabel(k.route) +
+		",code=" + quoteLabel(k.code) +
+		",outcome=" + quoteLabel(k.outcome)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(v string) string {
+	return `"` + labelReplacer.Replace(v) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
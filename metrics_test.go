@@ -0,0 +1,52 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	router := New()
+	router.Metrics = NewMetrics(0.5, 0.1)
+	var inflight string
+	router.Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		var sb strings.Builder
+		router.Metrics.WriteTo(&sb)
+		inflight = sb.String()
+	})
+	router.Post("/users", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.WriteHeader(http.StatusCreated)
+	})
+	router.Get("/a/", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	for _, target := range []string{"/users/1", "/users/2", "/none", "/a"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BREW", "/users", nil))
+
+	assert.Contains(t, inflight, `router_requests_in_flight{method="GET",route="/users/:id"} 1`)
+
+	rw := httptest.NewRecorder()
+	router.Metrics.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rw.Body.String()
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rw.Header().Get("Content-Type"))
+	assert.Contains(t, body, `router_requests_total{method="GET",route="/users/:id",code="200",outcome="found"} 2`)
+	assert.Contains(t, body, `router_requests_total{method="POST",route="/users",code="201",outcome="found"} 1`)
+	assert.Contains(t, body, `router_requests_total{method="GET",route="",code="404",outcome="not_found"} 1`)
+	assert.Contains(t, body, `router_requests_total{method="GET",route="",code="301",outcome="redirect"} 1`)
+	assert.Contains(t, body, `router_requests_total{method="OTHER",route="",code="405",outcome="method_not_allowed"} 1`)
+	assert.Contains(t, body, `router_requests_in_flight{method="GET",route="/users/:id"} 0`)
+	assert.Contains(t, body, `router_request_duration_seconds_bucket{method="GET",route="/users/:id",code="200",outcome="found",le="0.1"} 2`)
+	assert.Contains(t, body, `router_request_duration_seconds_bucket{method="GET",route="/users/:id",code="200",outcome="found",le="+Inf"} 2`)
+	assert.Contains(t, body, `router_request_duration_seconds_count{method="GET",route="/users/:id",code="200",outcome="found"} 2`)
+	assert.True(t, strings.Index(body, `le="0.1"`) < strings.Index(body, `le="0.5"`))
+}
+
+func TestQuoteLabel(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c\nd"`, quoteLabel("a\"b\\c\nd"))
+}
@@ -0,0 +1,68 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Produces returns a RouteOption which declares the media types the route
+// responds with. Several routes may share a method and pattern with
+// different media types; the router chooses the one preferred by the Accept
+// header of the request, sets Vary: Accept and answers 406 if none is
+// acceptable. The negotiated type is set as the default Content-Type of the
+// response.
+func Produces(types ...string) RouteOption {
+	return func(rt *route) {
+		rt.produces = append(rt.produces, types...)
+	}
+}
+
+// fallbackQuality ranks the routes which don't declare Produces below any
+// media type the client explicitly accepts.
+const fallbackQuality = 1e-9
+
+// negotiate chooses among candidates, which all accept the request, the
+// route producing the media type with the highest quality in accept. Ties go
+// to the earliest route, and to the earliest media type within a route.
+func negotiate(candidates []*route, accept string) selection {
+	var available []string
+	for _, rt := range candidates {
+		available = append(available, rt.produces...)
+	}
+	if len(available) == 0 {
+		return selection{route: candidates[0]}
+	}
+
+	var best *route
+	var mediaType string
+	quality := 0.0
+	for _, rt := range candidates {
+		if len(rt.produces) == 0 {
+			if quality < fallbackQuality {
+				best, mediaType, quality = rt, "", fallbackQuality
+			}
+			continue
+		}
+
+		for _, t := range rt.produces {
+			q := 1.0
+			if accept != "" {
+				q = acceptQuality(accept, t)
+			}
+			if q > quality {
+				best, mediaType, quality = rt, t, q
+			}
+		}
+	}
+
+	if best == nil {
+		return selection{available: available, status: http.StatusNotAcceptable}
+	}
+	return selection{route: best, mediaType: mediaType, available: available}
+}
+
+// concreteMediaType reports whether t names a single media type rather than
+// a range such as "text/*".
+func concreteMediaType(t string) bool {
+	return t != "" && !strings.HasSuffix(t, "/*")
+}
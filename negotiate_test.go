@@ -0,0 +1,90 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProduces(t *testing.T) {
+	router := New()
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("html"))
+	}, Produces("text/html"))
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("json"))
+	}, Produces("application/json"))
+
+	cases := []struct {
+		accept      string
+		status      int
+		body        string
+		contentType string
+	}{
+		{"", http.StatusOK, "html", "text/html"},
+		{"application/json", http.StatusOK, "json", "application/json"},
+		{"text/html;q=0.5, application/json", http.StatusOK, "json", "application/json"},
+		{"text/*, application/json;q=0.9", http.StatusOK, "html", "text/html"},
+		{"application/*;q=0.8, */*;q=0.1", http.StatusOK, "json", "application/json"},
+		{"image/png", http.StatusNotAcceptable, "406 not acceptable\n\navailable: text/html, application/json\n", ""},
+		{"application/json;q=0, text/html;q=0", http.StatusNotAcceptable, "", ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/a", nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		assert.Equal(t, c.status, rw.Code, c.accept)
+		assert.Equal(t, "Accept", rw.Header().Get("Vary"))
+		if c.body != "" {
+			assert.Equal(t, c.body, rw.Body.String())
+		}
+		if c.contentType != "" {
+			assert.Equal(t, c.contentType, rw.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestProducesFallback(t *testing.T) {
+	router := New()
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("csv"))
+	}, Produces("text/csv"))
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("fallback"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/a", nil)
+	req.Header.Set("Accept", "application/json")
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, req)
+	assert.Equal(t, "fallback", rw.Body.String())
+
+	res := router.Lookup(http.MethodGet, "/a")
+	assert.Equal(t, "text/csv", res.MediaType)
+	assert.Equal(t, []string{"text/csv"}, res.Available)
+
+	// the fallback may also be registered first
+	router.Get("/b", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("fallback"))
+	})
+	router.Get("/b", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("csv"))
+	}, Produces("text/csv"))
+
+	for accept, body := range map[string]string{"text/csv": "csv", "application/json": "fallback"} {
+		req := httptest.NewRequest(http.MethodGet, "/b", nil)
+		req.Header.Set("Accept", accept)
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		assert.Equal(t, body, rw.Body.String(), accept)
+	}
+
+	assert.Panics(t, func() {
+		router.Get("/b", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	})
+}
@@ -0,0 +1,560 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteDoc documents a route in the OpenAPI document, see Doc.
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+
+	// Operation ID, the route name if empty.
+	OperationID string
+
+	// Parameters of the route. Path parameters which are not listed are
+	// documented as strings.
+	Params []ParamDoc
+
+	// Value of the request body type, such as CreateUser{}. Its schema is
+	// derived by reflection, using the json struct tags.
+	Request any
+
+	// Values of the response body types by status code, nil for an empty
+	// body. A 200 response without body is documented if empty.
+	Responses map[int]any
+}
+
+// ParamDoc documents a parameter of a route.
+type ParamDoc struct {
+	Name string
+
+	// Location of the parameter: "path", "query", "header" or "cookie".
+	// "path" if empty.
+	In string
+
+	Description string
+	Required    bool
+
+	// Value of the parameter type, such as 0 for an integer. String if nil.
+	Type any
+}
+
+// Doc returns a RouteOption attaching OpenAPI documentation to the route.
+func Doc(doc RouteDoc) RouteOption {
+	return func(rt *route) {
+		rt.doc = &doc
+	}
+}
+
+// OpenAPI generates an OpenAPI 3.1 document describing the routes of a
+// Router, from their patterns and the RouteDoc, Consumes and Produces
+// options they were registered with.
+type OpenAPI struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []string
+}
+
+// Document returns the OpenAPI document for the routes of r, ready to be
+// encoded as JSON or YAML.
+func (o OpenAPI) Document(r *Router) map[string]any {
+	info := map[string]any{
+		"title":   o.Title,
+		"version": o.Version,
+	}
+	if o.Description != "" {
+		info["description"] = o.Description
+	}
+
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info":    info,
+	}
+	if len(o.Servers) > 0 {
+		servers := make([]any, len(o.Servers))
+		for i, url := range o.Servers {
+			servers[i] = map[string]any{"url": url}
+		}
+		doc["servers"] = servers
+	}
+
+	g := &schemaGenerator{components: make(map[string]any)}
+	paths := make(map[string]any)
+	r.tree.walk(func(n *node) {
+		methods := make([]string, 0, len(n.handlers))
+		for method := range n.handlers {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			routes := n.handlers[method]
+			if len(routes) == 0 || (method != anyMethod && !slices.Contains(openAPIMethods, method)) {
+				continue
+			}
+
+			path, params := openAPIPath(routes[0].expansion(n.pattern))
+			item, _ := paths[path].(map[string]any)
+			if item == nil {
+				item = make(map[string]any)
+				paths[path] = item
+			}
+
+			// Any routes are documented for the operations the path has no
+			// route of its own for
+			if method == anyMethod {
+				for _, m := range openAPIMethods {
+					if len(n.handlers[m]) == 0 {
+						item[strings.ToLower(m)] = g.operation(routes, params)
+					}
+				}
+				continue
+			}
+			item[strings.ToLower(method)] = g.operation(routes, params)
+		}
+	})
+
+	doc["paths"] = paths
+	if len(g.components) > 0 {
+		doc["components"] = map[string]any{"schemas": g.components}
+	}
+	return doc
+}
+
+// JSON returns the OpenAPI document for the routes of r encoded as JSON.
+func (o OpenAPI) JSON(r *Router) ([]byte, error) {
+	return json.MarshalIndent(o.Document(r), "", "  ")
+}
+
+// YAML returns the OpenAPI document for the routes of r encoded as YAML.
+func (o OpenAPI) YAML(r *Router) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, o.Document(r), 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Handler returns a Handle serving the OpenAPI document for the routes of r,
+// as YAML if the request path ends with .yaml or .yml or the request prefers
+// application/yaml, and as JSON otherwise.
+func (o OpenAPI) Handler(r *Router) Handle {
+	return func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		accept := req.Header.Get("Accept")
+		yaml := strings.HasSuffix(req.URL.Path, ".yaml") || strings.HasSuffix(req.URL.Path, ".yml") ||
+			(accept != "" && acceptQuality(accept, "application/yaml") > acceptQuality(accept, "application/json"))
+
+		var body []byte
+		var err error
+		if yaml {
+			rw.Header().Set("Content-Type", "application/yaml")
+			body, err = o.YAML(r)
+		} else {
+			rw.Header().Set("Content-Type", "application/json")
+			body, err = o.JSON(r)
+		}
+		if err != nil {
+			rw.Header().Del("Content-Type")
+			renderError(rw, req, NewProblem(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		rw.Write(body)
+	}
+}
+
+// openAPIMethods are the methods an OpenAPI path item has operations for,
+// CONNECT and extension methods are left out of the document.
+var openAPIMethods = []string{
+	http.MethodGet,
+	http.MethodPut,
+	http.MethodPost,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+	http.MethodPatch,
+	http.MethodTrace,
+}
+
+// openAPIPath converts pattern to an OpenAPI path, turning ":id" and "*path"
+// into "{id}" and "{path}", and returns the path parameters.
+func openAPIPath(pattern string) (string, []map[string]any) {
+	frags := strings.Split(pattern, "/")
+	var params []map[string]any
+	for i, frag := range frags {
+		if isMixedSegment(frag) {
+			// registered patterns are valid
+			parts, _ := parseSegment(frag)
+			var sb strings.Builder
+			for _, part := range parts {
+				if part.param == "" {
+					sb.WriteString(part.literal)
+					continue
+				}
+				params = append(params, pathParam(part.param))
+				sb.WriteString("{" + part.param + "}")
+			}
+			frags[i] = sb.String()
+			continue
+		}
+
+		if frag == "" || (frag[0] != ':' && frag[0] != '*') {
+			frags[i] = unescapeLiteral(frag)
+			continue
+		}
+
+		name := frag[1:]
+		param := pathParam(name)
+		if frag[0] == '*' {
+			param["description"] = "Wildcard matching the rest of the path, slashes included."
+			param["x-wildcard"] = true
+		}
+		params = append(params, param)
+		frags[i] = "{" + name + "}"
+	}
+	return strings.Join(frags, "/"), params
+}
+
+func pathParam(name string) map[string]any {
+	return map[string]any{
+		"name":     name,
+		"in":       "path",
+		"required": true,
+		"schema":   map[string]any{"type": "string"},
+	}
+}
+
+// operation documents the routes registered for a method on a node. The
+// first route is documented, the media types produced by the others are
+// added to its responses.
+func (g *schemaGenerator) operation(routes []*route, pathParams []map[string]any) map[string]any {
+	rt := routes[0]
+	doc := rt.doc
+	if doc == nil {
+		doc = &RouteDoc{}
+	}
+
+	op := map[string]any{}
+	if doc.Summary != "" {
+		op["summary"] = doc.Summary
+	}
+	if doc.Description != "" {
+		op["description"] = doc.Description
+	}
+	if len(doc.Tags) > 0 {
+		op["tags"] = doc.Tags
+	}
+	if doc.Deprecated {
+		op["deprecated"] = true
+	}
+	if id := doc.OperationID; id != "" {
+		op["operationId"] = id
+	} else if rt.name != "" {
+		op["operationId"] = rt.name
+	}
+
+	var params []any
+	documented := make(map[string]ParamDoc)
+	for _, p := range doc.Params {
+		if p.In == "" || p.In == "path" {
+			documented[p.Name] = p
+		}
+	}
+	for _, param := range pathParams {
+		if p, ok := documented[param["name"].(string)]; ok {
+			if p.Description != "" {
+				param["description"] = p.Description
+			}
+			if p.Type != nil {
+				param["schema"] = g.schema(reflect.TypeOf(p.Type))
+			}
+		}
+		params = append(params, param)
+	}
+	for _, p := range doc.Params {
+		if p.In == "" || p.In == "path" {
+			continue
+		}
+		param := map[string]any{
+			"name":   p.Name,
+			"in":     p.In,
+			"schema": map[string]any{"type": "string"},
+		}
+		if p.Required {
+			param["required"] = true
+		}
+		if p.Description != "" {
+			param["description"] = p.Description
+		}
+		if p.Type != nil {
+			param["schema"] = g.schema(reflect.TypeOf(p.Type))
+		}
+		params = append(params, param)
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if doc.Request != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  g.content(reflect.TypeOf(doc.Request), rt.consumes),
+		}
+	}
+
+	var produces []string
+	for _, rt := range routes {
+		produces = append(produces, rt.produces...)
+	}
+	responses := make(map[string]any)
+	for status, body := range doc.Responses {
+		resp := map[string]any{"description": http.StatusText(status)}
+		if body != nil {
+			resp["content"] = g.content(reflect.TypeOf(body), produces)
+		}
+		responses[strconv.Itoa(status)] = resp
+	}
+	if len(responses) == 0 {
+		resp := map[string]any{"description": http.StatusText(http.StatusOK)}
+		if len(produces) > 0 {
+			content := make(map[string]any)
+			for _, t := range produces {
+				content[t] = map[string]any{}
+			}
+			resp["content"] = content
+		}
+		responses["200"] = resp
+	}
+	op["responses"] = responses
+
+	return op
+}
+
+// content documents a body of type t in each of the media types, or as
+// application/json if there are none.
+func (g *schemaGenerator) content(t reflect.Type, types []string) map[string]any {
+	if len(types) == 0 {
+		types = []string{"application/json"}
+	}
+
+	schema := g.schema(t)
+	content := make(map[string]any, len(types))
+	for _, mt := range types {
+		content[mt] = map[string]any{"schema": schema}
+	}
+	return content
+}
+
+// schemaGenerator derives JSON schemas from Go types. Named struct types are
+// collected as components and referenced.
+type schemaGenerator struct {
+	components map[string]any
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+
+		name := t.Name()
+		if _, ok := g.components[name]; !ok {
+			// reserve the name first, the struct may refer to itself
+			g.components[name] = map[string]any{}
+			g.components[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	g.fields(t, properties, &required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fields adds the JSON fields of the struct type t to properties, following
+// the rules of encoding/json for tags and embedded structs.
+func (g *schemaGenerator) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			g.fields(ft, properties, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		schema := g.schema(f.Type)
+		if strings.Contains(opts, "string") {
+			schema = map[string]any{"type": "string"}
+		}
+		properties[name] = schema
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// writeYAML writes v, made of maps, slices and scalars, as a YAML block.
+func writeYAML(buf *bytes.Buffer, v any, indent int) error {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			buf.WriteString(pad + yamlScalar(k) + ":")
+			if err := writeYAMLValue(buf, v[k], indent); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, e := range v {
+			buf.WriteString(pad + "-")
+			if err := writeYAMLValue(buf, e, indent); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("router: can't encode %T as a YAML block", v)
+	}
+	return nil
+}
+
+// writeYAMLValue writes v after a key or a dash, on the same line for
+// scalars and empty collections, on the following lines otherwise.
+func writeYAMLValue(buf *bytes.Buffer, v any, indent int) error {
+	switch e := v.(type) {
+	case map[string]any:
+		if len(e) == 0 {
+			buf.WriteString(" {}\n")
+			return nil
+		}
+		buf.WriteString("\n")
+		return writeYAML(buf, e, indent+1)
+	case []map[string]any:
+		s := make([]any, len(e))
+		for i := range e {
+			s[i] = e[i]
+		}
+		return writeYAMLValue(buf, s, indent)
+	case []string:
+		s := make([]any, len(e))
+		for i := range e {
+			s[i] = e[i]
+		}
+		return writeYAMLValue(buf, s, indent)
+	case []any:
+		if len(e) == 0 {
+			buf.WriteString(" []\n")
+			return nil
+		}
+		buf.WriteString("\n")
+		return writeYAML(buf, e, indent+1)
+	default:
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(" " + string(b) + "\n")
+		return nil
+	}
+}
+
+// yamlScalar quotes s unless it is a plain YAML key.
+func yamlScalar(s string) string {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			b, _ := json.Marshal(s)
+			return string(b)
+		}
+	}
+	switch strings.ToLower(s) {
+	case "", "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		return `"` + s + `"`
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return `"` + s + `"`
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email,omitempty"`
+	Created  time.Time `json:"created"`
+	Friends  []*testUser
+	internal string
+}
+
+type testCreateUser struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	router := New()
+	handler := func(rw http.ResponseWriter, req *http.Request, _ Params) {}
+	router.Get("/users/:id", handler, Name("getUser"), Doc(RouteDoc{
+		Summary: "Get a user",
+		Tags:    []string{"users"},
+		Params: []ParamDoc{
+			{Name: "id", Description: "User ID", Type: int64(0)},
+			{Name: "expand", In: "query", Type: false},
+		},
+		Responses: map[int]any{http.StatusOK: testUser{}, http.StatusNotFound: nil},
+	}))
+	router.Post("/users", handler, Consumes("application/json"), Doc(RouteDoc{
+		Request:   testCreateUser{},
+		Responses: map[int]any{http.StatusCreated: &testUser{}},
+	}))
+	router.Get("/static/*filepath", handler)
+	router.Get("/reports/:year.:ext", handler)
+	router.Any("/hooks/:id", handler)
+	router.Post("/hooks/:id", handler, Doc(RouteDoc{Summary: "Post a hook"}))
+	router.Handle("PROPFIND", "/dav", handler)
+
+	api := OpenAPI{Title: "Test", Version: "1.0.0"}
+	router.Get("/openapi.json", api.Handler(router))
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	paths := doc["paths"].(map[string]any)
+	assert.Contains(t, paths, "/users/{id}")
+	assert.Contains(t, paths, "/users")
+	assert.Contains(t, paths, "/static/{filepath}")
+	assert.Contains(t, paths, "/openapi.json")
+
+	get := paths["/users/{id}"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, "getUser", get["operationId"])
+	assert.Equal(t, "Get a user", get["summary"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "id", "in": "path", "required": true, "description": "User ID",
+			"schema": map[string]any{"type": "integer", "format": "int64"}},
+		map[string]any{"name": "expand", "in": "query", "schema": map[string]any{"type": "boolean"}},
+	}, get["parameters"])
+	responses := get["responses"].(map[string]any)
+	assert.Equal(t, map[string]any{"description": "Not Found"}, responses["404"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/testUser"},
+		responses["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"])
+
+	user := doc["components"].(map[string]any)["schemas"].(map[string]any)["testUser"].(map[string]any)
+	assert.Equal(t, []any{"Friends", "created", "id", "name"}, user["required"])
+	properties := user["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, properties["created"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/testUser"}}, properties["Friends"])
+	assert.NotContains(t, properties, "internal")
+
+	post := paths["/users"].(map[string]any)["post"].(map[string]any)
+	body := post["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/testCreateUser"}, body["schema"])
+
+	wildcard := paths["/static/{filepath}"].(map[string]any)["get"].(map[string]any)["parameters"].([]any)[0].(map[string]any)
+	assert.Equal(t, true, wildcard["x-wildcard"])
+
+	hooks := paths["/hooks/{id}"].(map[string]any)
+	assert.Len(t, hooks, 8)
+	assert.Equal(t, "Post a hook", hooks["post"].(map[string]any)["summary"])
+	assert.NotContains(t, paths, "/dav")
+
+	report := paths["/reports/{year}.{ext}"].(map[string]any)["get"].(map[string]any)["parameters"].([]any)
+	assert.Len(t, report, 2)
+	assert.Equal(t, "ext", report[1].(map[string]any)["name"])
+}
+
+func TestOpenAPIYAML(t *testing.T) {
+	router := New()
+	router.Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Doc(RouteDoc{Tags: []string{"users"}}))
+
+	body, err := OpenAPI{Title: "Test", Version: "1"}.YAML(router)
+	assert.NoError(t, err)
+	assert.Equal(t, `info:
+  title: "Test"
+  version: "1"
+openapi: "3.1.0"
+paths:
+  "/users/{id}":
+    get:
+      parameters:
+        -
+          in: "path"
+          name: "id"
+          required: true
+          schema:
+            type: "string"
+      responses:
+        "200":
+          description: "OK"
+      tags:
+        - "users"
+`, string(body))
+}
@@ -0,0 +1,61 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// methodOverrideHeader and methodOverrideField carry the method a POST
+// request asks to be routed as, see Router.MethodOverride.
+const (
+	methodOverrideHeader = "X-HTTP-Method-Override"
+	methodOverrideField  = "_method"
+)
+
+// overrideMethod returns the method the POST request req asks to be routed
+// as, if it is in the MethodOverride allow-list, or an empty string. The
+// X-HTTP-Method-Override header is read first, then the "_method" query
+// parameter. The body is not read: it is still unrouted, so the route's
+// MaxBodySize and Consumes would not apply yet. The value is not
+// case-sensitive.
+func (r *Router) overrideMethod(req *http.Request) string {
+	if len(r.MethodOverride) == 0 || req.Method != http.MethodPost {
+		return ""
+	}
+
+	method := req.Header.Get(methodOverrideHeader)
+	if method == "" {
+		method = req.URL.Query().Get(methodOverrideField)
+	}
+
+	method = strings.ToUpper(method)
+	if method == "" || method == http.MethodPost || !slices.Contains(r.MethodOverride, method) {
+		return ""
+	}
+	return method
+}
+
+// applyMethodOverride returns req routed as the method it asks for, see
+// Router.MethodOverride, and records the original method in info.
+func (r *Router) applyMethodOverride(req *http.Request, info *RouteInfo) *http.Request {
+	method := r.overrideMethod(req)
+	if method == "" {
+		return req
+	}
+
+	info.OriginalMethod = req.Method
+	info.method = method
+	override := *req
+	override.Method = method
+	return &override
+}
+
+// OriginalMethod returns the method req was sent with, before
+// Router.MethodOverride changed it, or req.Method.
+func OriginalMethod(req *http.Request) string {
+	if info := RouteInfoFromContext(req.Context()); info != nil && info.OriginalMethod != "" {
+		return info.OriginalMethod
+	}
+	return req.Method
+}
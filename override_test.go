@@ -0,0 +1,114 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodOverride(t *testing.T) {
+	router := New()
+	router.MethodOverride = []string{http.MethodPut, http.MethodDelete}
+	var served, original, title string
+	handler := func(name string) Handle {
+		return func(rw http.ResponseWriter, req *http.Request, ps Params) {
+			served, original, title = name, OriginalMethod(req), req.FormValue("title")
+		}
+	}
+	router.Post("/items/:id", handler("post"))
+	router.Put("/items/:id", handler("put"))
+	router.Delete("/items/:id", handler("delete"))
+	router.Patch("/items/:id", handler("patch"))
+
+	serve := func(req *http.Request) int {
+		served, original, title = "", "", ""
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/items/1", nil)
+	req.Header.Set("X-HTTP-Method-Override", "PUT")
+	serve(req)
+	assert.Equal(t, "put", served)
+	assert.Equal(t, http.MethodPost, original)
+	assert.Equal(t, http.MethodPost, req.Method)
+
+	serve(httptest.NewRequest(http.MethodPost, "/items/1?_method=delete", nil))
+	assert.Equal(t, "delete", served)
+
+	// form bodies are not read before routing
+	req = httptest.NewRequest(http.MethodPost, "/items/1?_method=PUT", strings.NewReader("_method=DELETE&title=new"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	serve(req)
+	assert.Equal(t, "put", served)
+	assert.Equal(t, "new", title)
+	req = httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader("_method=PUT"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	serve(req)
+	assert.Equal(t, "post", served)
+
+	// not in the allow-list
+	serve(httptest.NewRequest(http.MethodPost, "/items/1?_method=PATCH", nil))
+	assert.Equal(t, "post", served)
+	assert.Equal(t, http.MethodPost, original)
+
+	// only POST requests are overridden
+	req = httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("X-HTTP-Method-Override", "DELETE")
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(req))
+
+	router.MethodOverride = nil
+	serve(httptest.NewRequest(http.MethodPost, "/items/1?_method=PUT", nil))
+	assert.Equal(t, "post", served)
+}
+
+func TestMethodOverrideBodyLimits(t *testing.T) {
+	router := New()
+	router.MethodOverride = []string{http.MethodPut}
+	var read int
+	handler := func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		req.ParseForm()
+		read = len(req.PostForm.Get("data"))
+	}
+	router.Post("/items", handler, MaxBodySize(10))
+	router.Put("/items", handler, MaxBodySize(10), Consumes("application/json"))
+
+	form := "data=" + strings.Repeat("x", 100000)
+	for target, status := range map[string]int{
+		"/items":             http.StatusRequestEntityTooLarge,
+		"/items?_method=PUT": http.StatusUnsupportedMediaType,
+	} {
+		read = 0
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		assert.Equal(t, status, rw.Code, target)
+		assert.Equal(t, 0, read, target)
+	}
+}
+
+func TestMethodOverrideAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.MethodOverride = []string{http.MethodDelete}
+	router.Delete("/items/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	handler := AccessLog(AccessLogConfig{
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+	})(router)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/items/1?_method=DELETE", nil))
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "DELETE", record["method"])
+	assert.Equal(t, "POST", record["original_method"])
+	assert.Equal(t, "/items/:id", record["pattern"])
+}
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// PanicError is the value passed to Router.PanicHandler when a handler
+// panics.
+type PanicError struct {
+	// Value recovered from the panic.
+	Value any
+
+	// Pattern of the matched route, empty if the panic happened in NoRoute,
+	// NoMethod or before a route was matched.
+	Pattern string
+
+	// Stack trace of the panicking goroutine.
+	Stack []byte
+
+	// ResponseStarted reports whether the status line had already been
+	// written when the handler panicked. WriteHeader calls made after that are
+	// dropped.
+	ResponseStarted bool
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic serving %s: %v", e.Pattern, e.Value)
+}
+
+// DefaultPanicHandler logs the panic with its stack trace through
+// slog.Default and answers 500, rendered by Router.ErrorRenderer, unless the
+// response has already started. It can be assigned to Router.PanicHandler.
+func DefaultPanicHandler(rw http.ResponseWriter, req *http.Request, v any) {
+	pe, ok := v.(*PanicError)
+	if !ok {
+		pe = &PanicError{Value: v}
+	}
+
+	slog.ErrorContext(req.Context(), "router: panic serving request",
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.String("pattern", pe.Pattern),
+		slog.Any("panic", pe.Value),
+		slog.String("stack", string(pe.Stack)),
+	)
+
+	if !pe.ResponseStarted {
+		renderError(rw, req, NewProblem(http.StatusInternalServerError, ""))
+	}
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicHandler(t *testing.T) {
+	router := New()
+	router.Get("/a/:b", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		panic("boom")
+	})
+	router.Get("/started", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	})
+
+	assert.Panics(t, func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a/x", nil))
+	})
+
+	var got *PanicError
+	router.PanicHandler = func(rw http.ResponseWriter, req *http.Request, v any) {
+		got = v.(*PanicError)
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a/x", nil))
+	assert.Equal(t, http.StatusInternalServerError, rw.Code)
+	assert.Equal(t, "boom", got.Value)
+	assert.Equal(t, "/a/:b", got.Pattern)
+	assert.False(t, got.ResponseStarted)
+	assert.Contains(t, string(got.Stack), "panic_test.go")
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/started", nil))
+	assert.Equal(t, http.StatusAccepted, rw.Code)
+	assert.True(t, got.ResponseStarted)
+
+	router.NoRoute = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		panic("no route")
+	})
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/none", nil))
+	assert.Equal(t, http.StatusInternalServerError, rw.Code)
+	assert.Equal(t, "", got.Pattern)
+
+	router.Get("/abort", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		panic(http.ErrAbortHandler)
+	})
+	assert.Panics(t, func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
+
+func TestDefaultPanicHandler(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	router := New()
+	router.PanicHandler = DefaultPanicHandler
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		panic("boom")
+	})
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	assert.Equal(t, http.StatusInternalServerError, rw.Code)
+	assert.Contains(t, buf.String(), "pattern=/a")
+	assert.Contains(t, buf.String(), "panic=boom")
+}
@@ -0,0 +1,239 @@
+package router
+
+import (
+	"fmt"
+	"math/bits"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// splitPattern splits a pattern in the syntax of http.ServeMux,
+// "[METHOD ][HOST]/PATH", into its parts.
+func splitPattern(pattern string) (method, host, path string) {
+	rest := pattern
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		method, rest = pattern[:i], strings.TrimLeft(pattern[i:], " \t")
+	}
+
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		panic(fmt.Sprintf(`pattern must contain a path beginning with '/': "%s"`, pattern))
+	}
+	return method, rest[:i], rest[i:]
+}
+
+// normalizePattern rewrites the wildcards of http.ServeMux into the syntax of
+// the tree: {name} becomes :name, {name...} becomes *name and a trailing {$}
+// is dropped, since patterns only ever match exactly. A pattern may use
+// either syntax, not both.
+func normalizePattern(pattern string) string {
+	p, err := convertPattern(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+	return p
+}
+
+// convertPattern is normalizePattern returning an error for malformed
+// patterns.
+func convertPattern(pattern string) (string, error) {
+	if !strings.ContainsAny(pattern, "{}") {
+		return pattern, nil
+	}
+
+	frags := strings.Split(pattern, "/")
+	for i, frag := range frags {
+		if frag == "" {
+			continue
+		}
+
+		if frag[0] == '*' || indexParam(frag) >= 0 {
+			return "", fmt.Errorf(`must not mix {name} with :name or *name parameters: "%s"`, pattern)
+		}
+
+		if !strings.ContainsAny(frag, "{}") {
+			continue
+		}
+
+		if frag[0] != '{' || strings.IndexByte(frag, '}') != len(frag)-1 {
+			mixed, err := convertMixedSegment(frag, pattern)
+			if err != nil {
+				return "", err
+			}
+			frags[i] = mixed
+			continue
+		}
+
+		name := frag[1 : len(frag)-1]
+		wildcard := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		switch {
+		case name == "$" && !wildcard:
+			if i != len(frags)-1 {
+				return "", fmt.Errorf(`{$} must be the last segment: "%s"`, pattern)
+			}
+			frags[i] = ""
+		case !nameRegexp.MatchString(name):
+			return "", fmt.Errorf(`invalid wildcard name "%s": "%s"`, name, pattern)
+		case wildcard:
+			frags[i] = "*" + name
+		default:
+			frags[i] = ":" + name
+		}
+	}
+
+	return strings.Join(frags, "/"), nil
+}
+
+// convertMixedSegment rewrites a segment mixing literals and {name}
+// parameters, such as "{name}.{ext}", into ":name.:ext".
+func convertMixedSegment(frag, pattern string) (string, error) {
+	var sb strings.Builder
+	for frag != "" {
+		open := strings.IndexByte(frag, '{')
+		if open < 0 {
+			open = len(frag)
+		}
+		if strings.IndexByte(frag[:open], '}') >= 0 {
+			return "", fmt.Errorf(`unbalanced braces: "%s"`, pattern)
+		}
+		sb.WriteString(frag[:open])
+		if open == len(frag) {
+			break
+		}
+
+		end := strings.IndexByte(frag[open:], '}')
+		if end < 0 {
+			return "", fmt.Errorf(`unbalanced braces: "%s"`, pattern)
+		}
+		name := frag[open+1 : open+end]
+		if name == "$" || strings.HasSuffix(name, "...") {
+			return "", fmt.Errorf(`wildcard must be a whole segment: "%s"`, pattern)
+		}
+		if !nameRegexp.MatchString(name) {
+			return "", fmt.Errorf(`invalid wildcard name "%s": "%s"`, name, pattern)
+		}
+
+		frag = frag[open+end+1:]
+		if frag != "" && frag[0] == '{' {
+			return "", fmt.Errorf(`wildcards must be separated by a literal: "%s"`, pattern)
+		}
+		if frag != "" && isNameByte(frag[0]) {
+			return "", fmt.Errorf(`wildcard {%s} must not be followed by a letter, digit or '_': "%s"`, name, pattern)
+		}
+		sb.WriteString(":" + name)
+	}
+
+	return sb.String(), nil
+}
+
+// expandOptional returns the patterns matched by pattern, whose optional
+// parts are either enclosed in parentheses, "/docs(/:lang)", or parameter
+// segments followed by '?', "/posts/:page?". Patterns with more optional parts
+// come first, earlier parts before later ones.
+func expandOptional(pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "()?") {
+		return []string{pattern}, nil
+	}
+
+	frags := strings.Split(pattern, "/")
+	for i, frag := range frags {
+		if !strings.HasSuffix(frag, "?") {
+			continue
+		}
+		if i == 0 || (frag[0] != ':' && frag[0] != '*') || !nameRegexp.MatchString(frag[1:len(frag)-1]) {
+			return nil, fmt.Errorf(`'?' must follow a whole parameter segment: "%s"`, pattern)
+		}
+		frags[i-1] += "("
+		frags[i] = frag[:len(frag)-1] + ")"
+	}
+	expanded := strings.Join(frags, "/")
+	if strings.Contains(expanded, "?") {
+		return nil, fmt.Errorf(`'?' must follow a whole parameter segment: "%s"`, pattern)
+	}
+
+	type group struct{ start, end int }
+	var groups []group
+	start := -1
+	for i := 0; i < len(expanded); i++ {
+		switch expanded[i] {
+		case '(':
+			if start >= 0 {
+				return nil, fmt.Errorf(`optional parts must not be nested: "%s"`, pattern)
+			}
+			start = i
+		case ')':
+			if start < 0 {
+				return nil, fmt.Errorf(`unbalanced parentheses: "%s"`, pattern)
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf(`empty optional part: "%s"`, pattern)
+			}
+			groups = append(groups, group{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		return nil, fmt.Errorf(`unbalanced parentheses: "%s"`, pattern)
+	}
+
+	// bit n-1-g of a mask is set when group g is present
+	n := len(groups)
+	masks := make([]uint, 1<<n)
+	for i := range masks {
+		masks[i] = uint(len(masks) - 1 - i)
+	}
+	sort.SliceStable(masks, func(i, j int) bool {
+		return bits.OnesCount(masks[i]) > bits.OnesCount(masks[j])
+	})
+
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, mask := range masks {
+		var sb strings.Builder
+		prev := 0
+		for g, grp := range groups {
+			sb.WriteString(expanded[prev:grp.start])
+			if mask&(1<<(n-1-g)) != 0 {
+				sb.WriteString(expanded[grp.start+1 : grp.end])
+			}
+			prev = grp.end + 1
+		}
+		sb.WriteString(expanded[prev:])
+
+		p := sb.String()
+		if p == "" {
+			p = "/"
+		}
+		if !seen[p] {
+			seen[p] = true
+			patterns = append(patterns, p)
+		}
+	}
+
+	return patterns, nil
+}
+
+// HandlePattern registers a new request handle for a pattern in the syntax
+// of http.ServeMux, "[METHOD ][HOST]/PATH", such as "GET /items/{id}" or
+// "example.com/files/{path...}". A pattern without a method is registered
+// with Any, and a host guards the route with Host(host). As with
+// http.ServeMux, a GET route also serves the HEAD requests which no HEAD
+// route matches.
+func (r *RouterPrefix) HandlePattern(pattern string, handler Handle, opts ...RouteOption) {
+	method, host, path := splitPattern(pattern)
+	if host != "" {
+		opts = append([]RouteOption{Match(Host(host))}, opts...)
+	}
+	if method == http.MethodGet {
+		opts = append(opts[:len(opts):len(opts)], func(rt *route) {
+			rt.head = true
+		})
+	}
+
+	if method == "" {
+		method = anyMethod
+	}
+	r.Handle(method, path, handler, opts...)
+}
@@ -0,0 +1,215 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePattern(t *testing.T) {
+	cases := map[string]string{
+		"/items":                  "/items",
+		"/items/:id":              "/items/:id",
+		"/items/{id}":             "/items/:id",
+		"/items/{id}/tags/{tag}":  "/items/:id/tags/:tag",
+		"/files/{path...}":        "/files/*path",
+		"/{$}":                    "/",
+		"/items/{$}":              "/items/",
+		"/items/{id}/{$}":         "/items/:id/",
+		"/files/{name}.{ext}":     "/files/:name.:ext",
+		"/v{major}/items":         "/v:major/items",
+		"/report-{year}.csv":      "/report-:year.csv",
+		"/static/{dir}/{file...}": "/static/:dir/*file",
+	}
+	for pattern, expected := range cases {
+		assert.Equal(t, expected, normalizePattern(pattern), pattern)
+	}
+
+	for _, pattern := range []string{
+		"/items/{id}/:tag",
+		"/files/*path/{id}",
+		"/a:b/{id}",
+		"/files/{name}.{path...}",
+		"/files/x{$}",
+		"/items/{id}x",
+		"/items/{id",
+		"/items/id}",
+		"/items/{a}{b}",
+		"/items/{}",
+		"/items/{a-b}",
+		"/items/{...}",
+		"/{$}/items",
+		"/items/{$...}",
+	} {
+		assert.Panics(t, func() { normalizePattern(pattern) }, pattern)
+	}
+}
+
+func TestBracePatternTree(t *testing.T) {
+	colon, brace := New().tree, New().tree
+	for _, p := range [][2]string{
+		{"/", "/{$}"},
+		{"/users/", "/users/{$}"},
+		{"/items/:id", "/items/{id}"},
+		{"/items/:id/tags", "/items/{id}/tags"},
+		{"/files/*path", "/files/{path...}"},
+	} {
+		colon.insert(p[0])
+		brace.insert(p[1])
+	}
+	assert.Equal(t, colon, brace)
+
+	n, ps, _ := brace.find("/files/a/b.txt")
+	assert.Equal(t, "/files/*path", n.pattern)
+	assert.Equal(t, Params{"path": "a/b.txt"}, ps)
+}
+
+func TestHandlePattern(t *testing.T) {
+	router := New()
+	var served string
+	handler := func(name string) Handle {
+		return func(rw http.ResponseWriter, req *http.Request, ps Params) {
+			served = name + " " + ps["id"] + ps["path"]
+		}
+	}
+	router.HandlePattern("GET api.example.com/items/{id}", handler("api"))
+	router.HandlePattern("GET /items/{id}", handler("get"))
+	router.HandlePattern("DELETE  /items/{id}", handler("delete"))
+	router.HandlePattern("/files/{path...}", handler("files"))
+	router.Prefix("/users/:uid").Get("/posts/{id}", handler("post"))
+
+	serve := func(method, target string) (int, string) {
+		served = ""
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(method, target, nil))
+		return rw.Code, served
+	}
+
+	_, s := serve(http.MethodGet, "http://api.example.com/items/1")
+	assert.Equal(t, "api 1", s)
+	_, s = serve(http.MethodGet, "http://example.com/items/1")
+	assert.Equal(t, "get 1", s)
+	_, s = serve(http.MethodDelete, "/items/2")
+	assert.Equal(t, "delete 2", s)
+	_, s = serve(http.MethodPut, "/files/a/b")
+	assert.Equal(t, "files a/b", s)
+	_, s = serve(http.MethodGet, "/users/1/posts/2")
+	assert.Equal(t, "post 2", s)
+	_, s = serve("PROPFIND", "/files/a")
+	assert.Equal(t, "files a", s)
+	code, _ := serve(http.MethodPut, "/items/1")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	// GET routes serve HEAD, unless a HEAD route matches
+	code, s = serve(http.MethodHead, "/items/1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "get 1", s)
+	assert.Equal(t, []string{"DELETE", "GET", "HEAD"}, router.Lookup(http.MethodPut, "/items/1").Allowed)
+	router.HandlePattern("HEAD /items/{id}", handler("head"))
+	_, s = serve(http.MethodHead, "/items/1")
+	assert.Equal(t, "head 1", s)
+	router.Get("/plain", handler("plain"))
+	_, s = serve(http.MethodHead, "/plain")
+	assert.Equal(t, "", s)
+
+	info := router.Lookup(http.MethodGet, "/items/1")
+	assert.Equal(t, "/items/:id", info.Pattern)
+
+	assert.Panics(t, func() { router.HandlePattern("GET items", handler("x")) })
+	assert.Panics(t, func() { router.HandlePattern("GET /a/{id}/:x", handler("x")) })
+	assert.Panics(t, func() { router.Get("/items/{name}", handler("x")) })
+}
+
+func TestExpandOptional(t *testing.T) {
+	cases := map[string][]string{
+		"/posts":                {"/posts"},
+		"/posts/:page?":         {"/posts/:page", "/posts"},
+		"/docs(/:lang)":         {"/docs/:lang", "/docs"},
+		"/docs(/:lang)/guide":   {"/docs/:lang/guide", "/docs/guide"},
+		"/(:lang)":              {"/:lang", "/"},
+		"/:a?/:b?":              {"/:a/:b", "/:a", "/:b", "/"},
+		"/img/:name(.:ext)":     {"/img/:name.:ext", "/img/:name"},
+		"/files(/*path?)":       nil,
+		"/files/*path?":         {"/files/*path", "/files"},
+		"/a(/:b)/c(/:d)":        {"/a/:b/c/:d", "/a/:b/c", "/a/c/:d", "/a/c"},
+		"/posts/:page?/archive": {"/posts/:page/archive", "/posts/archive"},
+	}
+	for pattern, expected := range cases {
+		patterns, err := expandOptional(pattern)
+		if expected == nil {
+			assert.Error(t, err, pattern)
+			continue
+		}
+		assert.NoError(t, err, pattern)
+		assert.Equal(t, expected, patterns, pattern)
+	}
+
+	for _, pattern := range []string{
+		"/posts/page?",
+		"/posts/:page.:ext?",
+		"/docs(/:lang",
+		"/docs/:lang)",
+		"/docs(/(:lang))",
+		"/docs()",
+	} {
+		_, err := expandOptional(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
+
+func TestOptionalRoutes(t *testing.T) {
+	router := New()
+	var params Params
+	var pattern string
+	handler := func(rw http.ResponseWriter, req *http.Request, ps Params) {
+		params, pattern = ps, MatchedPattern(req)
+	}
+	router.Get("/posts/:page?", handler, Name("posts"))
+	router.Prefix("/docs(/{lang})").Get("/guide", handler, Name("guide"))
+
+	serve := func(target string) int {
+		params, pattern = nil, ""
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, target, nil))
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/posts"))
+	assert.Nil(t, params)
+	assert.Equal(t, "/posts/:page?", pattern)
+	assert.Equal(t, http.StatusOK, serve("/posts/2"))
+	assert.Equal(t, Params{"page": "2"}, params)
+	assert.Equal(t, http.StatusOK, serve("/docs/guide"))
+	assert.Nil(t, params)
+	assert.Equal(t, "/docs(/:lang)/guide", pattern)
+	assert.Equal(t, http.StatusOK, serve("/docs/fr/guide"))
+	assert.Equal(t, Params{"lang": "fr"}, params)
+
+	assert.Len(t, router.Routes(), 2)
+	assert.Equal(t, "/docs/:lang/guide", router.Lookup(http.MethodGet, "/docs/fr/guide").expanded)
+	paths := OpenAPI{}.Document(router)["paths"].(map[string]any)
+	assert.Contains(t, paths, "/posts/{page}")
+	assert.Contains(t, paths, "/posts")
+
+	path, err := router.Path("posts", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/posts", path)
+	path, err = router.Path("posts", Params{"page": "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/posts/3", path)
+	path, err = router.Path("guide", Params{"lang": "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/docs/de/guide", path)
+	path, err = router.Path("guide", Params{"lang": ""})
+	assert.NoError(t, err)
+	assert.Equal(t, "/docs/guide", path)
+
+	assert.Panics(t, func() {
+		router.Get("/posts", handler)
+	})
+	assert.Panics(t, func() {
+		router.Get("/a(/:b)(/:c)", handler)
+	})
+}
@@ -0,0 +1,177 @@
+package router
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Problem describes an error response produced by the router itself, in the
+// shape of an RFC 7807 problem details object.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Methods allowed for a 405.
+	Allowed []string `json:"allowed,omitempty"`
+
+	// Media types available for a 406.
+	Available []string `json:"available,omitempty"`
+
+	// Target of a redirect.
+	Location string `json:"location,omitempty"`
+
+	// Patterns suggested for a 404, see Router.MaxSuggestions.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// NewProblem returns a Problem for status with its standard title.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// ErrorRenderer writes the response for a Problem produced by the router.
+// Headers such as Allow and Location are already set when it is called.
+type ErrorRenderer func(rw http.ResponseWriter, req *http.Request, p *Problem)
+
+// RenderProblemJSON is an ErrorRenderer which always answers with
+// application/problem+json.
+func RenderProblemJSON(rw http.ResponseWriter, req *http.Request, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = req.URL.Path
+	}
+
+	rw.Header().Set("Content-Type", "application/problem+json")
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(p.Status)
+	json.NewEncoder(rw).Encode(p)
+}
+
+var problemTemplate = template.Must(template.New("problem").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Status}} {{.Title}}</title></head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+{{if .Detail}}<p>{{.Detail}}</p>
+{{end}}{{if .Location}}<p><a href="{{.Location}}">{{.Location}}</a></p>
+{{end}}{{if .Allowed}}<p>Allowed methods: {{range $i, $m := .Allowed}}{{if $i}}, {{end}}{{$m}}{{end}}</p>
+{{end}}{{if .Available}}<p>Available media types: {{range $i, $t := .Available}}{{if $i}}, {{end}}{{$t}}{{end}}</p>
+{{end}}{{if .Suggestions}}<p>Did you mean:</p>
+<ul>
+{{range .Suggestions}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+// RenderProblem is an ErrorRenderer which answers with
+// application/problem+json, text/html or text/plain, whichever the Accept
+// header of the request prefers. JSON is used when the request has no Accept
+// header.
+func RenderProblem(rw http.ResponseWriter, req *http.Request, p *Problem) {
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		RenderProblemJSON(rw, req, p)
+		return
+	}
+
+	best, quality := "", 0.0
+	for _, t := range []string{"application/problem+json", "application/json", "text/html", "text/plain"} {
+		if q := acceptQuality(accept, t); q > quality {
+			best, quality = t, q
+		}
+	}
+
+	switch best {
+	case "text/html":
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.WriteHeader(p.Status)
+		problemTemplate.Execute(rw, p)
+	case "text/plain":
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.Header().Set("X-Content-Type-Options", "nosniff")
+		rw.WriteHeader(p.Status)
+		rw.Write([]byte(p.text()))
+	default:
+		RenderProblemJSON(rw, req, p)
+	}
+}
+
+// text renders p as plain text.
+func (p *Problem) text() string {
+	var sb strings.Builder
+	sb.WriteString(http.StatusText(p.Status))
+	if p.Detail != "" {
+		sb.WriteString(": " + p.Detail)
+	}
+	sb.WriteString("\n")
+	if p.Location != "" {
+		sb.WriteString("\nLocation: " + p.Location + "\n")
+	}
+	if len(p.Allowed) > 0 {
+		sb.WriteString("\nAllowed methods: " + strings.Join(p.Allowed, ", ") + "\n")
+	}
+	if len(p.Available) > 0 {
+		sb.WriteString("\nAvailable media types: " + strings.Join(p.Available, ", ") + "\n")
+	}
+	if len(p.Suggestions) > 0 {
+		sb.WriteString("\nDid you mean:\n")
+		for _, s := range p.Suggestions {
+			sb.WriteString("  " + s + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// renderError answers req with p, using the ErrorRenderer of the router
+// serving req, or the router's historical plain responses if it has none.
+func renderError(rw http.ResponseWriter, req *http.Request, p *Problem) {
+	if p.Location != "" {
+		rw.Header().Set("Location", p.Location)
+	}
+	if len(p.Allowed) > 0 {
+		rw.Header().Set("Allow", strings.Join(p.Allowed, ", "))
+	}
+
+	var r *Router
+	if info, _ := req.Context().Value(routeInfoKey{}).(*RouteInfo); info != nil {
+		r = info.router
+	}
+	if r != nil && r.ErrorRenderer != nil {
+		r.ErrorRenderer(rw, req, p)
+		return
+	}
+
+	switch {
+	case p.Location != "":
+		http.Redirect(rw, req, p.Location, p.Status)
+	case p.Status == http.StatusMethodNotAllowed:
+		rw.WriteHeader(p.Status)
+		rw.Write(default405Body)
+	case p.Status == http.StatusNotAcceptable:
+		rw.WriteHeader(p.Status)
+		rw.Write(default406Body)
+		if len(p.Available) > 0 {
+			rw.Write([]byte("\n\navailable: " + strings.Join(p.Available, ", ") + "\n"))
+		}
+	case p.Status == http.StatusNotFound && r != nil && r.MaxSuggestions > 0:
+		notFoundWithSuggestions(rw, req, p.Suggestions)
+	case p.Status == http.StatusNotFound:
+		http.NotFound(rw, req)
+	default:
+		msg := http.StatusText(p.Status)
+		if p.Detail != "" {
+			msg += ": " + p.Detail
+		}
+		http.Error(rw, msg, p.Status)
+	}
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderProblemJSON(t *testing.T) {
+	router := New()
+	router.ErrorRenderer = RenderProblemJSON
+	router.PanicHandler = DefaultPanicHandler
+	router.Get("/a", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/b/", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/panic", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		panic("boom")
+	})
+
+	cases := []struct {
+		method, target string
+		status         int
+		header, value  string
+	}{
+		{http.MethodGet, "/none", http.StatusNotFound, "", ""},
+		{"BREW", "/a", http.StatusMethodNotAllowed, "Allow", "GET"},
+		{http.MethodGet, "/b", http.StatusMovedPermanently, "Location", "/b/"},
+		{http.MethodGet, "/panic", http.StatusInternalServerError, "", ""},
+	}
+	for _, c := range cases {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(c.method, c.target, nil))
+		assert.Equal(t, c.status, rw.Code)
+		assert.Equal(t, "application/problem+json", rw.Header().Get("Content-Type"))
+		if c.header != "" {
+			assert.Equal(t, c.value, rw.Header().Get(c.header))
+		}
+
+		var p Problem
+		assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &p))
+		assert.Equal(t, c.status, p.Status)
+		assert.Equal(t, "about:blank", p.Type)
+		assert.Equal(t, http.StatusText(c.status), p.Title)
+		assert.Equal(t, c.target, p.Instance)
+	}
+}
+
+func TestRenderProblem(t *testing.T) {
+	router := New()
+	router.ErrorRenderer = RenderProblem
+	router.MaxSuggestions = 1
+	router.Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	cases := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", "application/problem+json", `"suggestions":["/users/:id"]`},
+		{"application/json", "application/problem+json", `"title":"Not Found"`},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", "text/html; charset=utf-8", "<li>/users/:id</li>"},
+		{"text/plain", "text/plain; charset=utf-8", "Not Found\n\nDid you mean:\n  /users/:id\n"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/user/1", nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		assert.Equal(t, http.StatusNotFound, rw.Code)
+		assert.Equal(t, c.contentType, rw.Header().Get("Content-Type"))
+		assert.Contains(t, rw.Body.String(), c.body)
+	}
+}
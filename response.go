@@ -0,0 +1,76 @@
+package router
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// responseWriter wraps a http.ResponseWriter to record the status code and the
+// number of bytes written, and to drop superfluous WriteHeader calls once the
+// response has started. It keeps http.Flusher and http.Hijacker working, and
+// http.ResponseController reaches the underlying writer through Unwrap.
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+
+	// informational responses don't start the final response
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.status = code
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil && !w.written {
+		w.status = http.StatusSwitchingProtocols
+		w.written = true
+	}
+	return conn, buf, err
+}
+
+// statusCode returns the status code of the response, 200 if the handler
+// returned without writing anything.
+func (w *responseWriter) statusCode() int {
+	if !w.written {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
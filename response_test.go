@@ -0,0 +1,29 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &responseWriter{ResponseWriter: rec}
+
+	w.Write([]byte("hello"))
+	w.WriteHeader(http.StatusInternalServerError)
+	assert.Equal(t, http.StatusOK, w.status)
+	assert.Equal(t, 5, w.size)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var rw http.ResponseWriter = w
+	_, ok := rw.(http.Flusher)
+	assert.True(t, ok)
+	rw.(http.Flusher).Flush()
+	assert.True(t, rec.Flushed)
+
+	_, _, err := http.NewResponseController(rw).Hijack()
+	assert.ErrorIs(t, err, http.ErrNotSupported)
+}
@@ -0,0 +1,269 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// route is a handler registered for a method on a tree node, together with
+// the options it was registered with.
+type route struct {
+	method   string
+	pattern  string
+	name     string
+	handler  Handle
+	matchers []Matcher
+	timeout  time.Duration
+	limiters []Limiter
+
+	consumes    []string
+	maxBodySize int64
+	produces    []string
+
+	doc *RouteDoc
+
+	// Patterns the route is registered for when its pattern has optional
+	// parts, see expandOptional.
+	expansions []string
+
+	// A GET route which also serves HEAD requests no HEAD route matches, as
+	// the GET patterns of http.ServeMux do, see HandlePattern.
+	head bool
+}
+
+// Route describes a registered route, see Router.Routes.
+type Route struct {
+	Method  string
+	Pattern string
+	Name    string
+
+	// Request media types accepted, see Consumes. Empty if any.
+	Consumes []string
+
+	// Response media types, see Produces. Empty if not negotiated.
+	Produces []string
+
+	// Limit of the request body in bytes, see MaxBodySize. Zero if none.
+	MaxBodySize int64
+
+	// Timeout of the handler, see Timeout. Zero if none.
+	Timeout time.Duration
+
+	// OpenAPI documentation, see Doc. Nil if none.
+	Doc *RouteDoc
+}
+
+// RouteOption configures a route when it is registered through Handle or one
+// of its shortcuts.
+type RouteOption func(*route)
+
+// Name returns a RouteOption which names the route. The name is reported by
+// RouteName for requests the route handles.
+func Name(name string) RouteOption {
+	return func(rt *route) {
+		rt.name = name
+	}
+}
+
+// Match returns a RouteOption which guards the route with matchers. Guarded
+// routes with the same method and pattern are tried in registration order,
+// and the first one whose matchers all accept the request handles it. The
+// route with neither Match nor Produces, if any, is tried last as their
+// fallback, whatever its registration order.
+func Match(matchers ...Matcher) RouteOption {
+	return func(rt *route) {
+		rt.matchers = append(rt.matchers, matchers...)
+	}
+}
+
+// serve calls the handler of rt, enforcing the policies it was registered
+// with.
+func (rt *route) serve(r *Router, rw http.ResponseWriter, req *http.Request, ps Params) {
+	if !rt.checkBody(rw, req) {
+		return
+	}
+
+	release := func() {}
+	if len(rt.limiters) > 0 {
+		var ok bool
+		if release, ok = rt.acquire(rw, req); !ok {
+			return
+		}
+	}
+
+	if rt.timeout > 0 {
+		// the handler may outlive a timeout, it releases the limiters itself
+		r.serveTimeout(rt, rw, req, ps, release)
+		return
+	}
+
+	defer release()
+	rt.handler(rw, req, ps)
+}
+
+// describe returns the public description of rt.
+func (rt *route) describe() Route {
+	return Route{
+		Method:      rt.method,
+		Pattern:     rt.pattern,
+		Name:        rt.name,
+		Consumes:    rt.consumes,
+		Produces:    rt.produces,
+		MaxBodySize: rt.maxBodySize,
+		Timeout:     rt.timeout,
+		Doc:         rt.doc,
+	}
+}
+
+// Routes returns the registered routes, sorted by pattern and method. Routes
+// sharing a method and pattern are listed in registration order.
+func (r *Router) Routes() []Route {
+	var routes []Route
+	for _, rt := range r.routes() {
+		routes = append(routes, rt.describe())
+	}
+	return routes
+}
+
+// routes returns the registered routes in the order of Routes.
+func (r *Router) routes() []*route {
+	var routes []*route
+	seen := make(map[*route]bool)
+	r.tree.walk(func(n *node) {
+		methods := make([]string, 0, len(n.handlers))
+		for method := range n.handlers {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			for _, rt := range n.handlers[method] {
+				// routes with optional parts are registered on several nodes
+				if !seen[rt] {
+					seen[rt] = true
+					routes = append(routes, rt)
+				}
+			}
+		}
+	})
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].pattern < routes[j].pattern
+	})
+	return routes
+}
+
+// expansion returns the pattern of rt registered on the node of pattern,
+// which differs from rt.pattern when it has optional parts.
+func (rt *route) expansion(pattern string) string {
+	for _, p := range rt.expansions {
+		if strings.EqualFold(p, pattern) {
+			return p
+		}
+	}
+	return rt.pattern
+}
+
+// selection is the outcome of choosing among the routes registered for a
+// method on a node.
+type selection struct {
+	route *route
+
+	// Media type negotiated with Accept, and all the media types produced by
+	// the candidates, when some of them declare Produces.
+	mediaType string
+	available []string
+
+	// Status code the router should answer with when route is nil.
+	status int
+}
+
+// selectRoute chooses the route handling req. Routes whose matchers reject
+// req are skipped. If some of the others declare Produces, the one producing
+// the media type preferred by Accept is chosen, otherwise the first one.
+//
+// When no route is chosen, the status is 406 if every route was rejected by
+// an Accept matcher or no produced media type is acceptable, 404 otherwise.
+func selectRoute(routes []*route, req *http.Request) selection {
+	status := 0
+	var candidates []*route
+	for _, rt := range routes {
+		m := rt.reject(req)
+		if m == nil {
+			candidates = append(candidates, rt)
+			continue
+		}
+
+		s := http.StatusNotFound
+		if sm, ok := m.(interface{ mismatchStatus() int }); ok {
+			s = sm.mismatchStatus()
+		}
+		if status == 0 {
+			status = s
+		} else if status != s {
+			status = http.StatusNotFound
+		}
+	}
+
+	if len(candidates) == 0 {
+		return selection{status: status}
+	}
+	return negotiate(candidates, req.Header.Get("Accept"))
+}
+
+// selectMethod chooses the route of n handling req among the routes for its
+// method, then for HEAD among the GET routes serving HEAD, then among the
+// routes registered with Any.
+func selectMethod(n *node, req *http.Request) selection {
+	var sel selection
+	if routes := n.handlers[req.Method]; len(routes) > 0 {
+		sel = selectRoute(routes, req)
+	}
+
+	if sel.route == nil && req.Method == http.MethodHead {
+		if routes := headRoutes(n); len(routes) > 0 {
+			if get := selectRoute(routes, req); get.route != nil || sel.status == 0 {
+				sel = get
+			}
+		}
+	}
+
+	if sel.route == nil {
+		if routes := n.handlers[anyMethod]; len(routes) > 0 {
+			if any := selectRoute(routes, req); any.route != nil || sel.status == 0 {
+				sel = any
+			}
+		}
+	}
+	return sel
+}
+
+// headRoutes returns the GET routes of n which also serve HEAD requests.
+func headRoutes(n *node) []*route {
+	var routes []*route
+	for _, rt := range n.handlers[http.MethodGet] {
+		if rt.head {
+			routes = append(routes, rt)
+		}
+	}
+	return routes
+}
+
+// unguarded reports whether rt has neither matchers nor produced media
+// types, and so accepts every request.
+func (rt *route) unguarded() bool {
+	return len(rt.matchers) == 0 && len(rt.produces) == 0
+}
+
+// reject returns the first matcher of rt which does not accept req, or nil.
+func (rt *route) reject(req *http.Request) Matcher {
+	for _, m := range rt.matchers {
+		if !m.Match(req) {
+			return m
+		}
+	}
+
+	return nil
+}
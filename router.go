@@ -2,10 +2,15 @@ package router
 
 import (
 	"net/http"
+	"runtime/debug"
 	"strings"
+	"time"
 )
 
-var default405Body = []byte("405 method not allowed")
+var (
+	default405Body = []byte("405 method not allowed")
+	default406Body = []byte("406 not acceptable")
+)
 
 // Router is a http.Handler which can be used to dispatch requests to different
 // handler functions via configurable routes
@@ -18,6 +23,38 @@ type Router struct {
 	// Ignore case when matching URL path.
 	IgnoreCase bool
 
+	// Match the percent-encoded path, req.URL.EscapedPath(), rather than the
+	// decoded req.URL.Path, so that "/files/a%2Fb" is the single segment
+	// "a/b" and not "/files/a/b". Each segment, and so each parameter value,
+	// is unescaped individually. Malformed escapes are answered with 400.
+	UseRawPath bool
+
+	// Limits on the length of the percent-encoded path, on its number of
+	// segments and on the length of each parameter value, 0 for none. Longer
+	// paths and paths with more segments are answered with 414 before being
+	// split, longer parameter values with 400. Segments are counted on the
+	// path as matched, so an encoded slash separates segments unless
+	// UseRawPath is set. MaxSegments also bounds the backtracking of
+	// wildcards in the middle of patterns, which may try every span of the
+	// path.
+	MaxPathLength  int
+	MaxSegments    int
+	MaxParamLength int
+
+	// Reject with 400, before matching, paths with "." or ".." segments,
+	// encoded slashes, backslashes, control characters including NUL,
+	// malformed percent-encodings and percent-encodings not in upper case,
+	// such as "%2f". Such paths are a common means of smuggling requests
+	// past proxies which normalize them differently.
+	StrictPaths bool
+
+	// Methods, such as PUT, PATCH and DELETE, which a POST request may ask
+	// to be routed as with the X-HTTP-Method-Override header or a "_method"
+	// query parameter, for HTML forms and clients limited to GET and POST.
+	// The body is never read to find the method. Empty disables method
+	// overriding. The original method is available through OriginalMethod.
+	MethodOverride []string
+
 	// Enables automatic redirection if the current route can't be matched but a
 	// handler for the path with (without) the trailing slash exists.
 	// TrailingSlashRedirect: /a/b/ -> /a/b
@@ -28,11 +65,44 @@ type Router struct {
 	// found. If it is not set, http.NotFound is used.
 	NoRoute http.Handler
 
+	// Maximum number of "did you mean" patterns suggested when no route is
+	// found, 0 disables suggestions. They are listed in the 404 body unless
+	// NoRoute is set, which can read them with Suggestions.
+	MaxSuggestions int
+
 	// Configurable http.Handler which is called when method is not allowed. If it is not set, http.NotFound is used.
 	NoMethod http.Handler
 
+	// Configurable function which is called with a *PanicError when a handler,
+	// NoRoute or NoMethod panics. Panics are not recovered if it is not set;
+	// DefaultPanicHandler answers 500 and logs the stack trace.
+	PanicHandler func(http.ResponseWriter, *http.Request, any)
+
+	// Configurable function which renders the errors the router answers with
+	// itself: 404, 405, 406, redirects, 400 for malformed paths and panics
+	// handled by DefaultPanicHandler. RenderProblem and RenderProblemJSON
+	// answer with RFC 7807 problem details. If it is not set, plain text
+	// bodies are used.
+	ErrorRenderer ErrorRenderer
+
+	// Status code answered when a route exceeds its Timeout before writing
+	// its response, http.StatusServiceUnavailable if zero.
+	TimeoutStatus int
+
+	// Optional per-route request metrics, see NewMetrics.
+	Metrics *Metrics
+
+	// Optional hooks called as requests are served, see Tracer.
+	Tracer Tracer
+
+	// Optional count of the requests served by each route, see NewCoverage.
+	Coverage *Coverage
+
 	// Methods which has been registered
 	allowMethods map[string]bool
+
+	// API versionings, see Versioning
+	versionings []*Versioning
 }
 
 // Handle is a function that can be registered to a route to handle HTTP
@@ -51,7 +121,7 @@ func New() *Router {
 		},
 		tree: &node{
 			children: make(map[string]*node),
-			handlers: make(map[string]Handle),
+			handlers: make(map[string][]*route),
 		},
 		TrailingSlashRedirect: true,
 		allowMethods:          make(map[string]bool),
@@ -63,48 +133,105 @@ func New() *Router {
 
 // ServeHTTP makes the router implement the http.Handler interface.
 func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	pattern := req.URL.Path
-	if r.IgnoreCase {
-		pattern = strings.ToLower(pattern)
-	}
+	req, info := withRouteInfo(req)
+	info.router = r
+	req = r.applyMethodOverride(req, info)
+	if r.PanicHandler != nil || r.Metrics != nil || r.Tracer != nil {
+		w := &responseWriter{ResponseWriter: rw}
+		rw = w
+
+		if r.Tracer != nil {
+			if ctx := r.Tracer.OnRequestStart(req); ctx != nil {
+				req = req.WithContext(ctx)
+			}
+			defer func() {
+				r.Tracer.OnRequestEnd(req, w.statusCode())
+			}()
+		}
 
-	// handle for matched request
-	n, ps, tsr := r.tree.find(pattern)
-	if n != nil {
-		if handler := n.handlers[req.Method]; handler != nil {
-			handler(rw, req, ps)
-			return
+		if r.Metrics != nil {
+			start := time.Now()
+			defer func() {
+				r.Metrics.end(r.metricsMethod(req.Method), info, w, time.Since(start))
+			}()
 		}
-	} else {
-		// handle for trailing slash redirect
-		if r.TrailingSlashRedirect && tsr {
-			path := req.URL.Path
-			if len(path) > 1 && path[len(path)-1] == '/' {
-				pattern = path[:len(path)-1]
-			} else if len(path) == 1 {
-				// do nothing
-			} else {
-				pattern = path + "/"
-			}
 
-			http.Redirect(rw, req, pattern, http.StatusMovedPermanently)
-			return
+		if r.PanicHandler != nil {
+			defer func() {
+				if v := recover(); v != nil {
+					stack := debug.Stack()
+					if hp, ok := v.(*handlerPanic); ok {
+						v, stack = hp.value, hp.stack
+					}
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+					r.PanicHandler(w, req, &PanicError{
+						Value:           v,
+						Pattern:         info.Pattern,
+						Stack:           stack,
+						ResponseStarted: w.written,
+					})
+				}
+			}()
 		}
 	}
 
-	if !r.allowMethods[req.Method] {
+	res := r.lookup(req)
+	info.resolve(res)
+	if r.Metrics != nil {
+		r.Metrics.begin(r.metricsMethod(req.Method), info)
+	}
+	if r.Tracer != nil {
+		r.Tracer.OnMatch(req, info.Pattern, info.Params)
+	}
+
+	if len(res.Available) > 0 {
+		rw.Header().Add("Vary", "Accept")
+	}
+
+	switch res.Kind {
+	case LookupFound:
+		if r.Coverage != nil {
+			r.Coverage.record(res.route)
+		}
+		if concreteMediaType(res.MediaType) {
+			rw.Header().Set("Content-Type", res.MediaType)
+		}
+		if res.version != nil {
+			res.version.writeHeaders(rw.Header())
+		}
+		res.route.serve(r, rw, req, res.Params)
+	case LookupBadRequest:
+		renderError(rw, req, NewProblem(res.status, res.detail))
+	case LookupRedirect:
+		p := NewProblem(http.StatusMovedPermanently, "")
+		p.Location = res.Location
+		renderError(rw, req, p)
+	case LookupNotAcceptable:
+		p := NewProblem(http.StatusNotAcceptable, "")
+		p.Available = res.Available
+		renderError(rw, req, p)
+	case LookupMethodNotAllowed:
 		if r.NoMethod != nil {
+			rw.Header().Set("Allow", strings.Join(res.Allowed, ", "))
 			r.NoMethod.ServeHTTP(rw, req)
 		} else {
-			rw.WriteHeader(http.StatusMethodNotAllowed)
-			rw.Write(default405Body)
+			p := NewProblem(http.StatusMethodNotAllowed, "")
+			p.Allowed = res.Allowed
+			renderError(rw, req, p)
+		}
+	default:
+		if r.MaxSuggestions > 0 {
+			info.Suggestions = r.suggest(req.URL.Path, r.MaxSuggestions)
 		}
-		return
-	}
 
-	if r.NoRoute != nil {
-		r.NoRoute.ServeHTTP(rw, req)
-	} else {
-		http.NotFound(rw, req)
+		if r.NoRoute != nil {
+			r.NoRoute.ServeHTTP(rw, req)
+		} else {
+			p := NewProblem(http.StatusNotFound, "")
+			p.Suggestions = info.Suggestions
+			renderError(rw, req, p)
+		}
 	}
 }
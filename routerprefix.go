@@ -11,50 +11,76 @@ type RouterPrefix struct {
 
 	// Prefix path of a router
 	basePath string
+
+	// Options applied to every route of the group, before the route's own
+	opts []RouteOption
 }
 
 // Get is a shortcut for router.Handle("GET", path, handle) with BasePath
-func (r *RouterPrefix) Get(pattern string, handler Handle) {
-	r.Handle(http.MethodGet, pattern, handler)
+func (r *RouterPrefix) Get(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodGet, pattern, handler, opts...)
 }
 
 // Post is a shortcut for router.Handle("POST", path, handle) with BasePath
-func (r *RouterPrefix) Post(pattern string, handler Handle) {
-	r.Handle(http.MethodPost, pattern, handler)
+func (r *RouterPrefix) Post(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodPost, pattern, handler, opts...)
 }
 
 // Put is a shortcut for router.Handle("PUT", path, handle) with BasePath
-func (r *RouterPrefix) Put(pattern string, handler Handle) {
-	r.Handle(http.MethodPut, pattern, handler)
+func (r *RouterPrefix) Put(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodPut, pattern, handler, opts...)
 }
 
 // Delete is a shortcut for router.Handle("DELETE", path, handle) with BasePath
-func (r *RouterPrefix) Delete(pattern string, handler Handle) {
-	r.Handle(http.MethodDelete, pattern, handler)
+func (r *RouterPrefix) Delete(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodDelete, pattern, handler, opts...)
 }
 
 // Options is a shortcut for router.Handle("OPTIONS", path, handle) with BasePath
-func (r *RouterPrefix) Options(pattern string, handler Handle) {
-	r.Handle(http.MethodOptions, pattern, handler)
+func (r *RouterPrefix) Options(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodOptions, pattern, handler, opts...)
 }
 
 // Trace is a shortcut for router.Handle("TRACE", path, handle) with BasePath
-func (r *RouterPrefix) Trace(pattern string, handler Handle) {
-	r.Handle(http.MethodTrace, pattern, handler)
+func (r *RouterPrefix) Trace(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodTrace, pattern, handler, opts...)
 }
 
 // Head is a shortcut for router.Handle("HEAD", path, handle) with BasePath
-func (r *RouterPrefix) Head(pattern string, handler Handle) {
-	r.Handle(http.MethodHead, pattern, handler)
+func (r *RouterPrefix) Head(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodHead, pattern, handler, opts...)
 }
 
 // Patch is a shortcut for router.Handle("PATCH", path, handle) with BasePath
-func (r *RouterPrefix) Patch(pattern string, handler Handle) {
-	r.Handle(http.MethodPatch, pattern, handler)
+func (r *RouterPrefix) Patch(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(http.MethodPatch, pattern, handler, opts...)
+}
+
+// Any registers handler for every method, including extension methods such
+// as PROPFIND. A route registered for the request method on the same pattern
+// takes precedence. Any routes are listed with the method "*".
+func (r *RouterPrefix) Any(pattern string, handler Handle, opts ...RouteOption) {
+	r.Handle(anyMethod, pattern, handler, opts...)
 }
 
-// Add prefix for a router, and return a new one with BasePath
-func (r *RouterPrefix) Prefix(prefix string) *RouterPrefix {
+// Methods is a shortcut for calling Handle with each of methods, such as
+// []string{"GET", "HEAD"} or []string{"PROPFIND", "MKCOL"}.
+func (r *RouterPrefix) Methods(methods []string, pattern string, handler Handle, opts ...RouteOption) {
+	for _, method := range methods {
+		if !validMethod(method) {
+			panic("invalid http method, '" + method + "'")
+		}
+	}
+
+	for _, method := range methods {
+		r.Handle(method, pattern, handler, opts...)
+	}
+}
+
+// Add prefix for a router, and return a new one with BasePath. The prefix
+// replaces the one of r, while the options are applied to every route
+// registered through the returned group, and to the groups derived from it.
+func (r *RouterPrefix) Prefix(prefix string, opts ...RouteOption) *RouterPrefix {
 	if prefix == "" {
 		panic("prefix must begin with '/', '" + prefix + "'")
 	}
@@ -64,41 +90,98 @@ func (r *RouterPrefix) Prefix(prefix string) *RouterPrefix {
 	}
 
 	return &RouterPrefix{
-		basePath: prefix,
+		basePath: normalizePattern(prefix),
 		router:   r.router,
+		opts:     append(r.opts[:len(r.opts):len(r.opts)], opts...),
 	}
 }
 
 // Handle registers a new request handle with the given path and method.
 // For GET, POST, PUT, PATCH and DELETE requests the respective shortcut
-// functions can be used.
-func (r *RouterPrefix) Handle(method, pattern string, handler Handle) {
-	if pattern[0] != '/' {
+// functions can be used. The method is case-sensitive and may be any token
+// of RFC 9110, such as the WebDAV methods PROPFIND and MKCOL; "*" is Any.
+// Several routes may share a method and pattern as long as at most one of
+// them has neither Match nor Produces; that one is the fallback of the
+// others, whatever its registration order. Parameters are written :name and
+// *name, or {name} and {name...} as with http.ServeMux. Optional parts are
+// enclosed in parentheses, "/docs(/:lang)", and a parameter segment followed
+// by '?', "/posts/:page?", is optional.
+func (r *RouterPrefix) Handle(method, pattern string, handler Handle, opts ...RouteOption) {
+	if pattern == "" || pattern[0] != '/' {
 		panic("path must begin with '/', '" + pattern + "'")
 	}
+	pattern = normalizePattern(pattern)
 
 	if r.basePath != "" {
 		pattern = r.basePath + pattern
 	}
 
-	if method == "" {
-		panic("invalid http method")
+	if !validMethod(method) {
+		panic("invalid http method, '" + method + "'")
 	}
 
 	router := r.router
 	if router.tree == nil {
 		router.tree = &node{
 			children: make(map[string]*node),
-			handlers: make(map[string]Handle),
+			handlers: make(map[string][]*route),
 		}
 	}
 
-	if router.IgnoreCase {
-		pattern = strings.ToLower(pattern)
+	rt := &route{
+		method:  method,
+		pattern: pattern,
+		handler: handler,
+	}
+	for _, opt := range r.opts {
+		opt(rt)
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	patterns, err := expandOptional(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+	if len(patterns) > 1 {
+		rt.expansions = patterns
 	}
 
 	if !router.allowMethods[method] {
 		router.allowMethods[method] = true
 	}
-	router.tree.insert(pattern).addHandle(method, handler)
+	if rt.head {
+		router.allowMethods[http.MethodHead] = true
+	}
+	for _, pattern := range patterns {
+		if router.IgnoreCase {
+			pattern = strings.ToLower(pattern)
+		}
+		router.tree.insert(pattern).addHandle(method, rt)
+	}
+}
+
+// anyMethod is the method key of the routes registered with Any.
+const anyMethod = "*"
+
+// validMethod reports whether method is a token of RFC 9110, section 5.6.2.
+func validMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+
+	for i := 0; i < len(method); i++ {
+		if !isTokenByte(method[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTokenByte(c byte) bool {
+	if '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
 }
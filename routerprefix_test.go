@@ -59,3 +59,56 @@ func TestPrefix(t *testing.T) {
 	assert.Equal(t, string(bodyBytes), serverResponse)
 	resp.Body.Close()
 }
+
+func TestAnyAndMethods(t *testing.T) {
+	router := New()
+	var served string
+	handler := func(name string) Handle {
+		return func(rw http.ResponseWriter, req *http.Request, _ Params) {
+			served = name + " " + req.Method
+		}
+	}
+	router.Any("/proxy/*path", handler("any"))
+	router.Post("/proxy/*path", handler("post"))
+	router.Methods([]string{http.MethodGet, http.MethodHead}, "/page", handler("page"))
+	router.Methods([]string{"PROPFIND", "MKCOL"}, "/dav/*path", handler("dav"))
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		served = ""
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(method, target, nil))
+		return rw
+	}
+
+	serve("PATCH", "/proxy/a")
+	assert.Equal(t, "any PATCH", served)
+	serve("BREW", "/proxy/a")
+	assert.Equal(t, "any BREW", served)
+	serve(http.MethodPost, "/proxy/a")
+	assert.Equal(t, "post POST", served)
+	serve(http.MethodHead, "/page")
+	assert.Equal(t, "page HEAD", served)
+	serve("PROPFIND", "/dav/a")
+	assert.Equal(t, "dav PROPFIND", served)
+
+	rw := serve("COPY", "/page")
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	assert.Equal(t, "GET, HEAD", rw.Header().Get("Allow"))
+	rw = serve("COPY", "/dav/a")
+	assert.Equal(t, "MKCOL, PROPFIND", rw.Header().Get("Allow"))
+	rw = serve("COPY", "/none")
+	assert.Equal(t, "GET, HEAD, MKCOL, POST, PROPFIND", rw.Header().Get("Allow"))
+
+	routes := router.Routes()
+	assert.Equal(t, Route{Method: "*", Pattern: "/proxy/*path"}, routes[len(routes)-2])
+
+	for _, method := range []string{"", "GET ", "PROP FIND", "GET/1", "(GET)", "GÉT"} {
+		assert.Panics(t, func() {
+			router.Handle(method, "/x", handler("x"))
+		}, method)
+	}
+	assert.Panics(t, func() {
+		router.Methods([]string{"GET", "BAD METHOD"}, "/y", handler("y"))
+	})
+	assert.Equal(t, LookupNotFound, router.Lookup(http.MethodGet, "/y").Kind)
+}
@@ -0,0 +1,298 @@
+// Package routertest provides helpers to test the routes of a router.Router
+// in-process, without starting a server.
+//
+//	rt := routertest.New(t, r)
+//	rt.Named("getUser", router.Params{"id": "42"}).
+//		Header("Accept", "application/json").
+//		Do().
+//		Status(http.StatusOK).
+//		Pattern("/users/:id").
+//		Param("id", "42").
+//		BodyContains(`"id":42`)
+//
+//	rt.Matches(http.MethodGet, "/users/42").Pattern("/users/:id")
+//	rt.NotMatches(http.MethodGet, "/users")
+package routertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cssivision/router"
+)
+
+// Tester builds requests for the routes of a router and serves them
+// in-process.
+type Tester struct {
+	tb     testing.TB
+	router *router.Router
+}
+
+// New returns a Tester for r reporting failures to tb.
+func New(tb testing.TB, r *router.Router) *Tester {
+	return &Tester{tb: tb, router: r}
+}
+
+// Request returns a request for method on pattern filled with the params
+// ps, such as Request("GET", "/users/:id", router.Params{"id": "42"}).
+func (t *Tester) Request(method, pattern string, ps router.Params) *Request {
+	t.tb.Helper()
+	path, err := router.ExpandPattern(pattern, ps)
+	if err != nil {
+		t.tb.Fatalf("routertest: %v", err)
+	}
+	return t.newRequest(method, path)
+}
+
+// Named returns a request for the route registered with router.Name(name),
+// filled with the params ps.
+func (t *Tester) Named(name string, ps router.Params) *Request {
+	t.tb.Helper()
+	rt, ok := t.router.Route(name)
+	if !ok {
+		t.tb.Fatalf("routertest: no route named %q", name)
+	}
+	return t.Request(rt.Method, rt.Pattern, ps)
+}
+
+func (t *Tester) newRequest(method, path string) *Request {
+	return &Request{
+		t:      t,
+		method: method,
+		path:   path,
+		query:  make(url.Values),
+		header: make(http.Header),
+	}
+}
+
+// Matches asserts that a route matches method and path, without serving the
+// request, and returns the match for further assertions.
+func (t *Tester) Matches(method, path string) *Match {
+	t.tb.Helper()
+	res := t.router.Lookup(method, path)
+	if res.Kind != router.LookupFound {
+		t.tb.Errorf("routertest: %s %s: expected a matching route, got %s", method, path, res.Kind)
+	}
+	return &Match{tb: t.tb, desc: method + " " + path, res: res}
+}
+
+// NotMatches asserts that no route matches method and path, without serving
+// the request.
+func (t *Tester) NotMatches(method, path string) {
+	t.tb.Helper()
+	if res := t.router.Lookup(method, path); res.Kind == router.LookupFound {
+		t.tb.Errorf("routertest: %s %s: expected no matching route, matched %s", method, path, res.Pattern)
+	}
+}
+
+// Match is the route matched by Tester.Matches.
+type Match struct {
+	tb   testing.TB
+	desc string
+	res  router.LookupResult
+}
+
+// Pattern asserts the pattern of the matched route.
+func (m *Match) Pattern(pattern string) *Match {
+	m.tb.Helper()
+	if m.res.Pattern != pattern {
+		m.tb.Errorf("routertest: %s: expected pattern %q, got %q", m.desc, pattern, m.res.Pattern)
+	}
+	return m
+}
+
+// Params asserts the params of the matched route.
+func (m *Match) Params(ps router.Params) *Match {
+	m.tb.Helper()
+	assertParams(m.tb, m.desc, ps, m.res.Params)
+	return m
+}
+
+// Request is a request under construction, see Tester.Request.
+type Request struct {
+	t      *Tester
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// Header sets the header key of the request to value.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query adds the query parameter key with value to the request.
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Body sets the body of the request and its Content-Type.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+// JSON sets the body of the request to v encoded as JSON.
+func (r *Request) JSON(v any) *Request {
+	r.t.tb.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.t.tb.Fatalf("routertest: %v", err)
+	}
+	return r.Body("application/json", body)
+}
+
+// Do serves the request and returns the response for assertions.
+func (r *Request) Do() *Response {
+	target := r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+
+	ctx, info := router.NewRouteInfoContext(req.Context())
+	rec := httptest.NewRecorder()
+	r.t.router.ServeHTTP(rec, req.WithContext(ctx))
+
+	return &Response{
+		tb:   r.t.tb,
+		desc: r.method + " " + target,
+		rec:  rec,
+		info: info,
+	}
+}
+
+// Response is the response to a request served by Request.Do.
+type Response struct {
+	tb   testing.TB
+	desc string
+	rec  *httptest.ResponseRecorder
+	info *router.RouteInfo
+}
+
+// Recorder returns the recorder holding the response.
+func (r *Response) Recorder() *httptest.ResponseRecorder {
+	return r.rec
+}
+
+// Status asserts the status code of the response.
+func (r *Response) Status(code int) *Response {
+	r.tb.Helper()
+	if r.rec.Code != code {
+		r.tb.Errorf("routertest: %s: expected status %d, got %d", r.desc, code, r.rec.Code)
+	}
+	return r
+}
+
+// Header asserts the value of the header key of the response.
+func (r *Response) Header(key, value string) *Response {
+	r.tb.Helper()
+	if got := r.rec.Header().Get(key); got != value {
+		r.tb.Errorf("routertest: %s: expected header %s %q, got %q", r.desc, key, value, got)
+	}
+	return r
+}
+
+// Body asserts the body of the response.
+func (r *Response) Body(body string) *Response {
+	r.tb.Helper()
+	if got := r.rec.Body.String(); got != body {
+		r.tb.Errorf("routertest: %s: expected body %q, got %q", r.desc, body, got)
+	}
+	return r
+}
+
+// BodyContains asserts that the body of the response contains s.
+func (r *Response) BodyContains(s string) *Response {
+	r.tb.Helper()
+	if got := r.rec.Body.String(); !strings.Contains(got, s) {
+		r.tb.Errorf("routertest: %s: expected body containing %q, got %q", r.desc, s, got)
+	}
+	return r
+}
+
+// JSON decodes the body of the response into v.
+func (r *Response) JSON(v any) *Response {
+	r.tb.Helper()
+	if err := json.Unmarshal(r.rec.Body.Bytes(), v); err != nil {
+		r.tb.Errorf("routertest: %s: decoding body: %v", r.desc, err)
+	}
+	return r
+}
+
+// Pattern asserts the pattern of the route which served the request, empty
+// if none matched.
+func (r *Response) Pattern(pattern string) *Response {
+	r.tb.Helper()
+	if r.info.Pattern != pattern {
+		r.tb.Errorf("routertest: %s: expected pattern %q, got %q", r.desc, pattern, r.info.Pattern)
+	}
+	return r
+}
+
+// Params asserts the params of the route which served the request.
+func (r *Response) Params(ps router.Params) *Response {
+	r.tb.Helper()
+	assertParams(r.tb, r.desc, ps, r.info.Params)
+	return r
+}
+
+// Param asserts the value of the param name of the route which served the
+// request.
+func (r *Response) Param(name, value string) *Response {
+	r.tb.Helper()
+	if got, ok := r.info.Params[name]; !ok || got != value {
+		r.tb.Errorf("routertest: %s: expected param %s %q, got %q", r.desc, name, value, got)
+	}
+	return r
+}
+
+func assertParams(tb testing.TB, desc string, want, got router.Params) {
+	tb.Helper()
+	if len(want) == 0 && len(got) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(want, got) {
+		tb.Errorf("routertest: %s: expected params %v, got %v", desc, want, got)
+	}
+}
+
+// CheckCoverage fails tb if less than percent of the routes of r served a
+// request since r.Coverage was set, listing the unexercised routes.
+func CheckCoverage(tb testing.TB, r *router.Router, percent float64) {
+	tb.Helper()
+	if r.Coverage == nil {
+		tb.Fatalf("routertest: router has no Coverage")
+	}
+
+	report := r.Coverage.Report(r)
+	if report.Percent() >= percent {
+		return
+	}
+
+	var sb strings.Builder
+	for _, rc := range report.Unexercised() {
+		sb.WriteString("\n\t" + rc.Method + " " + rc.Pattern)
+	}
+	tb.Errorf("routertest: route coverage %.1f%% is below %.1f%%, unexercised:%s", report.Percent(), percent, sb.String())
+}
@@ -0,0 +1,122 @@
+package routertest
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/cssivision/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRouter() *router.Router {
+	r := router.New()
+	r.Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, ps router.Params) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"id":%q,"q":%q}`, ps["id"], req.URL.Query().Get("q"))
+	}, router.Name("getUser"))
+	r.Post("/users", func(rw http.ResponseWriter, req *http.Request, _ router.Params) {
+		rw.WriteHeader(http.StatusCreated)
+	}, router.Consumes("application/json"))
+	return r
+}
+
+func TestTester(t *testing.T) {
+	rt := New(t, newRouter())
+
+	var body struct {
+		ID string `json:"id"`
+		Q  string `json:"q"`
+	}
+	rt.Named("getUser", router.Params{"id": "42"}).
+		Query("q", "x").
+		Do().
+		Status(http.StatusOK).
+		Header("Content-Type", "application/json").
+		Pattern("/users/:id").
+		Params(router.Params{"id": "42"}).
+		Param("id", "42").
+		BodyContains(`"id":"42"`).
+		JSON(&body)
+	assert.Equal(t, "x", body.Q)
+
+	rt.Request(http.MethodPost, "/users", nil).JSON(map[string]string{"name": "a"}).Do().Status(http.StatusCreated)
+	rt.Request(http.MethodPost, "/users", nil).Body("text/plain", []byte("a")).Do().Status(http.StatusUnsupportedMediaType)
+	rt.Request(http.MethodGet, "/none", nil).Do().Status(http.StatusNotFound).Pattern("").Body("404 page not found\n")
+
+	rt.Matches(http.MethodGet, "/users/42").Pattern("/users/:id").Params(router.Params{"id": "42"})
+	rt.NotMatches(http.MethodGet, "/users/42/posts")
+}
+
+// recorder is a testing.TB recording the failures reported to it. Fatalf
+// ends the goroutine like testing.T does, so checks must run through record.
+type recorder struct {
+	testing.TB
+	messages []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// record runs f in its own goroutine and returns the failures it reported.
+func record(f func(tb testing.TB)) []string {
+	rec := &recorder{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(rec)
+	}()
+	<-done
+	return rec.messages
+}
+
+func TestTesterFailures(t *testing.T) {
+	messages := record(func(tb testing.TB) {
+		New(tb, newRouter()).Request(http.MethodGet, "/users/:id", router.Params{"id": "1"}).Do().Status(http.StatusTeapot)
+	})
+	assert.Equal(t, []string{"routertest: GET /users/1: expected status 418, got 200"}, messages)
+
+	messages = record(func(tb testing.TB) {
+		New(tb, newRouter()).NotMatches(http.MethodGet, "/users/1")
+	})
+	assert.Equal(t, []string{"routertest: GET /users/1: expected no matching route, matched /users/:id"}, messages)
+
+	messages = record(func(tb testing.TB) {
+		New(tb, newRouter()).Matches(http.MethodGet, "/users/1").Pattern("/users/:name")
+	})
+	assert.Equal(t, []string{`routertest: GET /users/1: expected pattern "/users/:name", got "/users/:id"`}, messages)
+
+	messages = record(func(tb testing.TB) {
+		New(tb, newRouter()).Named("none", nil).Do()
+		tb.Errorf("not reached")
+	})
+	assert.Equal(t, []string{`routertest: no route named "none"`}, messages)
+}
+
+func TestCheckCoverage(t *testing.T) {
+	r := newRouter()
+	r.Coverage = router.NewCoverage()
+	rt := New(t, r)
+	rt.Named("getUser", router.Params{"id": "1"}).Do().Status(http.StatusOK)
+
+	CheckCoverage(t, r, 50)
+
+	messages := record(func(tb testing.TB) {
+		CheckCoverage(tb, r, 100)
+	})
+	assert.Equal(t, []string{"routertest: route coverage 50.0% is below 100.0%, unexercised:\n\tPOST /users"}, messages)
+
+	messages = record(func(tb testing.TB) {
+		CheckCoverage(tb, newRouter(), 0)
+	})
+	assert.Equal(t, []string{"routertest: router has no Coverage"}, messages)
+}
@@ -0,0 +1,168 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// segmentPart is either a literal or a named parameter of a pattern segment.
+type segmentPart struct {
+	literal string
+	param   string
+}
+
+// isMixedSegment reports whether the pattern segment frag mixes literals and
+// parameters, such as ":name.:ext", "v:major" or "report-:year.csv".
+func isMixedSegment(frag string) bool {
+	i := indexParam(frag)
+	if i < 0 || frag[0] == '*' {
+		return false
+	}
+	return i > 0 || !nameRegexp.MatchString(frag[1:])
+}
+
+// indexParam returns the index of the first ':' of frag which is not escaped
+// with a backslash, or -1.
+func indexParam(frag string) int {
+	for i := 0; i < len(frag); i++ {
+		switch frag[i] {
+		case '\\':
+			i++
+		case ':':
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeLiteral returns the literal pattern text frag without the
+// backslashes escaping ':', '*' and '\', so the pattern `/a/\:id` matches the
+// path "/a/:id".
+func unescapeLiteral(frag string) string {
+	if strings.IndexByte(frag, '\\') < 0 {
+		return frag
+	}
+	return literalReplacer.Replace(frag)
+}
+
+var literalReplacer = strings.NewReplacer(`\:`, ":", `\*`, "*", `\\`, `\`)
+
+// parseSegment splits a mixed pattern segment into its parts. A parameter
+// name is the longest run of letters, digits and underscores following ':',
+// and two parameters must be separated by a literal.
+func parseSegment(segment string) ([]segmentPart, error) {
+	var parts []segmentPart
+	frag := segment
+	for frag != "" {
+		i := indexParam(frag)
+		if i < 0 {
+			parts = append(parts, segmentPart{literal: unescapeLiteral(frag)})
+			break
+		}
+		if i > 0 {
+			parts = append(parts, segmentPart{literal: unescapeLiteral(frag[:i])})
+		} else if len(parts) > 0 {
+			return nil, fmt.Errorf(`parameters must be separated by a literal: "%s"`, segment)
+		}
+
+		frag = frag[i+1:]
+		end := 0
+		for end < len(frag) && isNameByte(frag[end]) {
+			end++
+		}
+		if end == 0 {
+			return nil, fmt.Errorf(`invalid named parameter: "%s"`, segment)
+		}
+		parts = append(parts, segmentPart{param: frag[:end]})
+		frag = frag[end:]
+	}
+
+	return parts, nil
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// segmentShape returns the segment of parts with the parameter names
+// removed, two mixed segments of the same shape match the same paths.
+func segmentShape(parts []segmentPart) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		if part.param != "" {
+			sb.WriteByte(':')
+		} else {
+			sb.WriteString(part.literal)
+		}
+	}
+	return sb.String()
+}
+
+// literalLength returns the number of literal bytes of parts, segments with
+// more literal bytes are more specific and tried first.
+func literalLength(parts []segmentPart) int {
+	n := 0
+	for _, part := range parts {
+		n += len(part.literal)
+	}
+	return n
+}
+
+// matchSegment matches the path segment s against parts and appends the
+// parameter values to values. A parameter matches a non-empty value and is
+// greedy: of all the ways to split s, the one giving the longest value to the
+// first parameter, then to the second and so on, is chosen. So ":name.:ext"
+// matches "a.tar.gz" with name "a.tar" and ext "gz".
+//
+// It runs in time linear in len(s) times the number of parts, whatever the
+// segment, so that no request can make matching backtrack without limit.
+func matchSegment(parts []segmentPart, s string, values []string) ([]string, bool) {
+	if first := parts[0]; first.param == "" && !strings.HasPrefix(s, first.literal) {
+		return nil, false
+	}
+	if last := parts[len(parts)-1]; last.param == "" && !strings.HasSuffix(s, last.literal) {
+		return nil, false
+	}
+
+	// ok[i*stride+pos] reports whether parts[i:] match s[pos:]
+	stride := len(s) + 1
+	ok := make([]bool, (len(parts)+1)*stride)
+	ok[len(parts)*stride+len(s)] = true
+	for i := len(parts) - 1; i >= 0; i-- {
+		row, next := ok[i*stride:(i+1)*stride], ok[(i+1)*stride:(i+2)*stride]
+		if part := parts[i]; part.param == "" {
+			for pos := 0; pos+len(part.literal) <= len(s); pos++ {
+				row[pos] = next[pos+len(part.literal)] && strings.HasPrefix(s[pos:], part.literal)
+			}
+			continue
+		}
+
+		// a parameter takes at least one byte, up to any position the rest
+		// of the parts match from
+		found := false
+		for pos := len(s) - 1; pos >= 0; pos-- {
+			found = found || next[pos+1]
+			row[pos] = found
+		}
+	}
+	if !ok[0] {
+		return nil, false
+	}
+
+	pos := 0
+	for i, part := range parts {
+		if part.param == "" {
+			pos += len(part.literal)
+			continue
+		}
+
+		next := ok[(i+1)*stride : (i+2)*stride]
+		end := len(s)
+		for !next[end] {
+			end--
+		}
+		values = append(values, s[pos:end])
+		pos = end
+	}
+	return values, true
+}
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchSegment(t *testing.T) {
+	cases := []struct {
+		segment string
+		s       string
+		values  []string
+	}{
+		{":name.:ext", "a.tar.gz", []string{"a.tar", "gz"}},
+		{":name.:ext", "a.", nil},
+		{":name.:ext", ".a", nil},
+		{"v:major", "v2", []string{"2"}},
+		{"v:major", "v", nil},
+		{"report-:year.csv", "report-2024.csv", []string{"2024"}},
+		{"report-:year.csv", "report-2024.txt", nil},
+		{":a-:b-:c.txt", "x-y-z-w.txt", []string{"x-y", "z", "w"}},
+		{":a-:b-:c.txt", "x-y.txt", nil},
+		{":name.:ext.gz", "a.b.c.gz", []string{"a.b", "c"}},
+	}
+	for _, c := range cases {
+		parts, err := parseSegment(c.segment)
+		assert.NoError(t, err, c.segment)
+		values, ok := matchSegment(parts, c.s, nil)
+		assert.Equal(t, c.values != nil, ok, c.segment+" "+c.s)
+		assert.Equal(t, c.values, values, c.segment+" "+c.s)
+	}
+}
+
+func TestMatchSegmentAdversarial(t *testing.T) {
+	router := New()
+	router.Get("/f/:a-:b-:c.txt", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/g/:name.:ext.gz", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+
+	// such segments used to backtrack for seconds before being rejected
+	start := time.Now()
+	assert.Equal(t, LookupNotFound, router.Lookup(http.MethodGet, "/f/"+strings.Repeat("-", 100000)).Kind)
+	assert.Equal(t, LookupNotFound, router.Lookup(http.MethodGet, "/f/x-"+strings.Repeat("x", 100000)+".txt").Kind)
+	assert.Equal(t, LookupFound, router.Lookup(http.MethodGet, "/f/"+strings.Repeat("-", 100000)+".txt").Kind)
+	assert.Equal(t, LookupNotFound, router.Lookup(http.MethodGet, "/g/"+strings.Repeat(".", 100000)).Kind)
+	assert.Equal(t, LookupNotFound, router.Lookup(http.MethodGet, "/g/"+strings.Repeat("a", 100000)+".gz").Kind)
+	assert.Equal(t, LookupFound, router.Lookup(http.MethodGet, "/g/"+strings.Repeat("a.", 100000)+"gz").Kind)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// checkPath enforces MaxPathLength and StrictPaths on the percent-encoded
+// request path raw, and MaxSegments on path, the path split into segments for
+// matching. It returns the status code and detail of the error answered for a
+// rejected path, or 0.
+func (r *Router) checkPath(raw, path string) (int, string) {
+	if r.MaxPathLength > 0 && len(raw) > r.MaxPathLength {
+		return http.StatusRequestURITooLong, "path too long"
+	}
+
+	if r.MaxSegments > 0 && strings.Count(path, "/") > r.MaxSegments {
+		return http.StatusRequestURITooLong, "too many path segments"
+	}
+
+	if r.StrictPaths {
+		if detail := strictPathError(raw); detail != "" {
+			return http.StatusBadRequest, detail
+		}
+	}
+
+	return 0, ""
+}
+
+// strictPathError describes why the percent-encoded path raw is rejected by
+// StrictPaths, or returns an empty string. It does not allocate.
+func strictPathError(raw string) string {
+	segmentStart := 0
+	dots, other := 0, false
+	for i := 0; i <= len(raw); i++ {
+		if i == len(raw) || raw[i] == '/' {
+			if i > segmentStart && !other && (dots == 1 || dots == 2) {
+				return "dot segment"
+			}
+			segmentStart, dots, other = i+1, 0, false
+			continue
+		}
+
+		c := raw[i]
+		if c == '%' {
+			if i+2 >= len(raw) || !isHex(raw[i+1]) || !isHex(raw[i+2]) {
+				return "malformed percent-encoding"
+			}
+			if isLowerHex(raw[i+1]) || isLowerHex(raw[i+2]) {
+				return "percent-encoding not in upper case"
+			}
+			c = unhex(raw[i+1])<<4 | unhex(raw[i+2])
+			i += 2
+
+			if c == '/' {
+				return "encoded slash"
+			}
+		}
+
+		switch {
+		case c == '\\':
+			return "backslash"
+		case c < 0x20 || c == 0x7f:
+			return "control character"
+		case c == '.':
+			dots++
+		default:
+			other = true
+		}
+	}
+
+	return ""
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func isLowerHex(c byte) bool {
+	return 'a' <= c && c <= 'f'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// checkParams enforces MaxParamLength on the values of ps.
+func (r *Router) checkParams(ps Params) bool {
+	if r.MaxParamLength <= 0 {
+		return true
+	}
+
+	for _, v := range ps {
+		if len(v) > r.MaxParamLength {
+			return false
+		}
+	}
+	return true
+}
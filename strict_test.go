@@ -0,0 +1,143 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictPathError(t *testing.T) {
+	cases := map[string]string{
+		"/":                 "",
+		"/a/b.c/..d/...":    "",
+		"/a%20b/%C3%A9":     "",
+		"/a/./b":            "dot segment",
+		"/a/../b":           "dot segment",
+		"/a/..":             "dot segment",
+		"/a/%2E%2E/b":       "dot segment",
+		"/a/.%2E":           "dot segment",
+		"/a%2Fb":            "encoded slash",
+		"/a%5Cb":            "backslash",
+		"/a\\b":             "backslash",
+		"/a%00b":            "control character",
+		"/a\x00b":           "control character",
+		"/a%7F":             "control character",
+		"/a%2fb":            "percent-encoding not in upper case",
+		"/%c3%a9":           "percent-encoding not in upper case",
+		"/a%2":              "malformed percent-encoding",
+		"/a%zz":             "malformed percent-encoding",
+		"/a%":               "malformed percent-encoding",
+		"/files/a.tar.gz/.": "dot segment",
+	}
+	for path, expected := range cases {
+		assert.Equal(t, expected, strictPathError(path), path)
+	}
+}
+
+func TestPathLimits(t *testing.T) {
+	router := New()
+	router.Get("/files/*path", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	router.MaxPathLength = 32
+	router.MaxSegments = 4
+	router.MaxParamLength = 8
+
+	serve := func(target string) int {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, target, nil))
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/users/12345678"))
+	assert.Equal(t, http.StatusBadRequest, serve("/users/123456789"))
+	assert.Equal(t, http.StatusOK, serve("/files/a/b/c"))
+	assert.Equal(t, http.StatusRequestURITooLong, serve("/files/a/b/c/d"))
+	assert.Equal(t, http.StatusRequestURITooLong, serve("/files/"+strings.Repeat("x", 32)))
+	assert.Equal(t, http.StatusNotFound, serve("/none/../users/1"))
+
+	// encoded slashes are separators unless UseRawPath is set
+	assert.Equal(t, http.StatusRequestURITooLong, serve("/files/a%2Fb%2Fc%2Fd"))
+	router.UseRawPath = true
+	assert.Equal(t, http.StatusOK, serve("/files/a%2Fb%2Fc%2Fd"))
+	router.UseRawPath = false
+
+	router.StrictPaths = true
+	assert.Equal(t, http.StatusBadRequest, serve("/files/../users/1"))
+	assert.Equal(t, http.StatusBadRequest, serve("/files/a%2Fb"))
+	assert.Equal(t, http.StatusOK, serve("/files/a%20b"))
+
+	router.ErrorRenderer = RenderProblemJSON
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/files/a%2fb", nil))
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+	assert.Contains(t, rw.Body.String(), "percent-encoding not in upper case")
+
+	res := router.Lookup(http.MethodGet, "/users/123456789")
+	assert.Equal(t, LookupBadRequest, res.Kind)
+}
+
+func FuzzStrictPathError(f *testing.F) {
+	for _, seed := range []string{"/", "/a/b", "/a/../b", "/a%2Fb", "/%2e%2E", "/a%00", "/a%", "/%C3%A9/."} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		if strictPathError(raw) != "" {
+			return
+		}
+
+		for _, segment := range strings.Split(raw, "/") {
+			s, err := url.PathUnescape(segment)
+			if err != nil {
+				t.Fatalf("%q: accepted malformed escape", raw)
+			}
+			if s == "." || s == ".." {
+				t.Fatalf("%q: accepted dot segment", raw)
+			}
+			if strings.ContainsAny(s, "/\\") || strings.IndexFunc(s, func(c rune) bool { return c < 0x20 || c == 0x7f }) >= 0 {
+				t.Fatalf("%q: accepted encoded slash or control character", raw)
+			}
+		}
+	})
+}
+
+func FuzzLookup(f *testing.F) {
+	router := New()
+	h := func(rw http.ResponseWriter, req *http.Request, _ Params) {}
+	router.Get("/users/:id", h)
+	router.Get("/users/:id/posts/:post", h)
+	router.Get("/files/:name.:ext", h)
+	router.Get("/repos/*path/blob/:ref", h)
+	router.Get("/static/*path", h)
+	router.Get("/docs(/:lang)", h)
+	router.MaxPathLength = 64
+	router.MaxSegments = 8
+	router.MaxParamLength = 16
+
+	for _, seed := range []string{"/", "/users/1", "/files/a.b.c", "/repos/a/b/blob/main", "/static/", "/docs/fr", "/users/1/posts/"} {
+		f.Add(seed, false)
+		f.Add(seed, true)
+	}
+
+	f.Fuzz(func(t *testing.T, path string, strict bool) {
+		router.StrictPaths = strict
+		router.UseRawPath = strict
+
+		res := router.Lookup(http.MethodGet, path)
+		if res.Kind != LookupFound {
+			return
+		}
+		if len(path) > router.MaxPathLength || strings.Count(path, "/") > router.MaxSegments {
+			t.Fatalf("%q: matched beyond the path limits", path)
+		}
+		for name, value := range res.Params {
+			if len(value) > router.MaxParamLength {
+				t.Fatalf("%q: parameter %s longer than the limit", path, name)
+			}
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxSuggestionDistance is the largest edit distance between a request path
+// and a pattern for the pattern to be suggested.
+const maxSuggestionDistance = 3
+
+// suggest returns up to max registered patterns closest to path, by
+// segment-wise edit distance.
+func (r *Router) suggest(path string, max int) []string {
+	if r.IgnoreCase {
+		path = strings.ToLower(path)
+	}
+	frags := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	type candidate struct {
+		pattern  string
+		distance int
+	}
+	var candidates []candidate
+	r.tree.walk(func(n *node) {
+		// the pattern of the first method in order, so that suggestions do
+		// not depend on map iteration
+		methods := make([]string, 0, len(n.handlers))
+		for method, routes := range n.handlers {
+			if len(routes) > 0 {
+				methods = append(methods, method)
+			}
+		}
+		if len(methods) == 0 {
+			return
+		}
+		sort.Strings(methods)
+
+		pattern := n.handlers[methods[0]][0].pattern
+		patternFrags := strings.Split(strings.TrimPrefix(n.pattern, "/"), "/")
+		if d := segmentDistance(frags, patternFrags); d <= maxSuggestionDistance {
+			candidates = append(candidates, candidate{pattern, d})
+		}
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var patterns []string
+	for i := 0; i < len(candidates) && i < max; i++ {
+		patterns = append(patterns, candidates[i].pattern)
+	}
+	return patterns
+}
+
+// segmentDistance is the edit distance between the path segments frags and
+// the pattern segments patternFrags. Replacing a segment costs the edit
+// distance between both segments, adding or removing one costs its length.
+// Distances above maxSuggestionDistance are only bounded from below.
+// Named parameters match any segment for free and a wildcard matches all the
+// remaining ones.
+func segmentDistance(frags, patternFrags []string) int {
+	if n := len(patternFrags); n > 0 && strings.HasPrefix(patternFrags[n-1], "*") && len(frags) >= n {
+		frags = append(frags[:n-1:n-1], strings.Join(frags[n-1:], "/"))
+	}
+
+	prev := make([]int, len(patternFrags)+1)
+	cur := make([]int, len(patternFrags)+1)
+	for j, p := range patternFrags {
+		prev[j+1] = prev[j] + segmentCost(p)
+	}
+
+	for _, f := range frags {
+		cur[0] = prev[0] + segmentCost(f)
+		for j, p := range patternFrags {
+			sub := prev[j]
+			if isMixedSegment(p) {
+				sub += mixedDistance(f, p)
+			} else if p == "" || (p[0] != ':' && p[0] != '*') {
+				sub += editDistance(f, unescapeLiteral(p))
+			}
+			cur[j+1] = min(sub, prev[j+1]+segmentCost(f), cur[j]+segmentCost(p))
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(patternFrags)]
+}
+
+// mixedDistance is 0 if the path segment f matches the mixed pattern segment
+// p, and the edit distance between f and the literals of p otherwise.
+func mixedDistance(f, p string) int {
+	parts, _ := parseSegment(p)
+	if _, ok := matchSegment(parts, f, nil); ok {
+		return 0
+	}
+
+	var literals strings.Builder
+	for _, part := range parts {
+		literals.WriteString(part.literal)
+	}
+	return editDistance(f, literals.String())
+}
+
+func segmentCost(frag string) int {
+	return max(len(frag), 1)
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and transpositions of adjacent bytes
+// cost 1. The distance is at least the difference of the lengths, which is
+// returned without aligning the strings when it exceeds
+// maxSuggestionDistance. Only the last three rows of the matrix are kept.
+func editDistance(a, b string) int {
+	if d := len(a) - len(b); d > maxSuggestionDistance || -d > maxSuggestionDistance {
+		return max(d, -d)
+	}
+
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d = min(d, prev2[j-2]+1)
+			}
+			cur[j] = d
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(b)]
+}
+
+// notFoundWithSuggestions answers 404 listing suggestions, as JSON if the
+// client prefers it to plain text.
+func notFoundWithSuggestions(rw http.ResponseWriter, req *http.Request, suggestions []string) {
+	accept := req.Header.Get("Accept")
+	if accept != "" && acceptQuality(accept, "application/json") > acceptQuality(accept, "text/plain") {
+		if suggestions == nil {
+			suggestions = []string{}
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("X-Content-Type-Options", "nosniff")
+		rw.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(rw).Encode(struct {
+			Error       string   `json:"error"`
+			Suggestions []string `json:"suggestions"`
+		}{"404 page not found", suggestions})
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("404 page not found\n")
+	if len(suggestions) > 0 {
+		sb.WriteString("\nDid you mean:\n")
+		for _, s := range suggestions {
+			sb.WriteString("  " + s + "\n")
+		}
+	}
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(http.StatusNotFound)
+	rw.Write([]byte(sb.String()))
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestions(t *testing.T) {
+	router := New()
+	router.MaxSuggestions = 2
+	handler := func(rw http.ResponseWriter, req *http.Request, _ Params) {}
+	router.Get("/users/:id", handler)
+	router.Get("/users/:id/posts", handler)
+	router.Get("/orders/:id", handler)
+	router.Get("/static/*filepath", handler)
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/usres/42", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "404 page not found\n\nDid you mean:\n  /users/:id\n", rw.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/statik/css/site.css", nil)
+	req.Header.Set("Accept", "application/json")
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, req)
+	var body struct {
+		Suggestions []string `json:"suggestions"`
+	}
+	assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+	assert.Equal(t, []string{"/static/*filepath"}, body.Suggestions)
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/completely/unrelated/path", nil))
+	assert.Equal(t, "404 page not found\n", rw.Body.String())
+
+	var got []string
+	router.NoRoute = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = Suggestions(req)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/user/42/post", nil))
+	assert.Equal(t, []string{"/users/:id/posts", "/users/:id"}, got)
+}
+
+func TestSuggestionsDeterministic(t *testing.T) {
+	router := New()
+	handler := func(rw http.ResponseWriter, req *http.Request, _ Params) {}
+	router.Get("/posts/:page?", handler)
+	router.Post("/posts", handler)
+	router.Delete("/posts", handler)
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, []string{"/posts"}, router.suggest("/post", 3))
+	}
+
+	start := time.Now()
+	assert.Nil(t, router.suggest("/"+strings.Repeat("x", 200000), 3))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSegmentDistance(t *testing.T) {
+	assert.Equal(t, 0, segmentDistance([]string{"users", "42"}, []string{"users", ":id"}))
+	assert.Equal(t, 1, segmentDistance([]string{"usres", "42"}, []string{"users", ":id"}))
+	assert.Equal(t, 0, segmentDistance([]string{"a", "b", "c"}, []string{"a", "*path"}))
+	assert.Equal(t, 5, segmentDistance([]string{"users"}, []string{"users", "posts"}))
+	assert.Equal(t, 0, segmentDistance([]string{"files", "a.txt"}, []string{"files", ":name.:ext"}))
+	assert.Greater(t, segmentDistance([]string{"report-2024.cvs"}, []string{"report-:year.csv"}), maxSuggestionDistance)
+	assert.Equal(t, 1, editDistance("kitten", "kittne"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 200000, editDistance(strings.Repeat("x", 200000), ""))
+}
@@ -0,0 +1,273 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Timeout returns a RouteOption which gives the route's handler d to serve a
+// request. The request context carries the deadline and is canceled when it
+// is exceeded; if the handler has not started its response by then, the
+// router answers with Router.TimeoutStatus and later writes fail with
+// http.ErrHandlerTimeout.
+//
+// Streaming handlers can extend the deadline with
+// http.ResponseController.SetWriteDeadline, or opt out of it by passing the
+// zero time.
+func Timeout(d time.Duration) RouteOption {
+	return func(rt *route) {
+		rt.timeout = d
+	}
+}
+
+// handlerPanic carries a panic raised in the goroutine running a handler
+// with a timeout to the goroutine serving the request.
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+// serveTimeout runs the handler of rt in its own goroutine, which calls
+// release once the handler returns, even after the router has answered.
+func (r *Router) serveTimeout(rt *route, rw http.ResponseWriter, req *http.Request, ps Params, release func()) {
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	tw := &timeoutWriter{
+		w:        rw,
+		h:        make(http.Header),
+		deadline: time.Now().Add(rt.timeout),
+		cancel:   cancel,
+		expired:  make(chan struct{}),
+	}
+	tw.mu.Lock()
+	tw.timer = time.AfterFunc(rt.timeout, tw.expire)
+	tw.mu.Unlock()
+	defer tw.timer.Stop()
+
+	tctx := &timeoutContext{Context: ctx, tw: tw}
+	req = req.WithContext(tctx)
+	done := make(chan struct{})
+	panicked := make(chan *handlerPanic, 1)
+	go func() {
+		defer release()
+		defer func() {
+			if v := recover(); v != nil {
+				panicked <- &handlerPanic{value: v, stack: debug.Stack()}
+			}
+		}()
+		rt.handler(tw, req, ps)
+		close(done)
+	}()
+
+	expired := tw.expired
+	for {
+		select {
+		case <-done:
+			return
+		case hp := <-panicked:
+			// ServeHTTP unwraps the panic for the PanicHandler, without
+			// one it reaches net/http and outer middleware as it was raised
+			if r.PanicHandler == nil {
+				panic(hp.value)
+			}
+			panic(hp)
+		case <-expired:
+			expired = nil
+
+			tw.mu.Lock()
+			timedOut := tw.timedOut
+			tw.mu.Unlock()
+
+			// a started response can't be replaced, let the handler finish
+			if timedOut {
+				status := r.TimeoutStatus
+				if status == 0 {
+					status = http.StatusServiceUnavailable
+				}
+				renderError(rw, req, NewProblem(status, "request timed out"))
+				return
+			}
+		}
+	}
+}
+
+// timeoutWriter is the http.ResponseWriter given to handlers with a timeout.
+// It holds its own header map so that the router can answer on its own once
+// the deadline is exceeded, while the handler may still be running.
+type timeoutWriter struct {
+	w http.ResponseWriter
+	h http.Header
+
+	mu          sync.Mutex
+	timer       *time.Timer
+	deadline    time.Time
+	cancel      context.CancelFunc
+	expired     chan struct{}
+	wroteHeader bool
+	timedOut    bool
+	exceeded    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writeHeader(code)
+}
+
+func (tw *timeoutWriter) writeHeader(code int) {
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+
+	dst := tw.w.Header()
+	for k, v := range tw.h {
+		dst[k] = v
+	}
+	if code < 200 && code != http.StatusSwitchingProtocols {
+		tw.w.WriteHeader(code)
+		return
+	}
+
+	tw.wroteHeader = true
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	tw.writeHeader(http.StatusOK)
+	return tw.w.Write(b)
+}
+
+// FlushError implements the interface used by http.ResponseController.
+func (tw *timeoutWriter) FlushError() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return http.ErrHandlerTimeout
+	}
+
+	tw.writeHeader(http.StatusOK)
+	return http.NewResponseController(tw.w).Flush()
+}
+
+// Flush implements http.Flusher.
+func (tw *timeoutWriter) Flush() {
+	tw.FlushError()
+}
+
+// Hijack implements http.Hijacker. A hijacked connection is no longer
+// subject to the route's timeout.
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+
+	conn, buf, err := http.NewResponseController(tw.w).Hijack()
+	if err == nil {
+		tw.wroteHeader = true
+		tw.deadline = time.Time{}
+		tw.timer.Stop()
+	}
+	return conn, buf, err
+}
+
+// SetWriteDeadline implements the interface used by http.ResponseController.
+// It moves the route's deadline to t, or removes it if t is zero, and passes
+// t on to the connection.
+func (tw *timeoutWriter) SetWriteDeadline(t time.Time) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return http.ErrHandlerTimeout
+	}
+
+	if !tw.exceeded {
+		tw.deadline = t
+		if t.IsZero() {
+			tw.timer.Stop()
+		} else {
+			tw.timer.Reset(time.Until(t))
+		}
+	}
+
+	err := http.NewResponseController(tw.w).SetWriteDeadline(t)
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// SetReadDeadline implements the interface used by http.ResponseController.
+func (tw *timeoutWriter) SetReadDeadline(t time.Time) error {
+	return http.NewResponseController(tw.w).SetReadDeadline(t)
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+// expire is called by the timer once the deadline may have passed.
+func (tw *timeoutWriter) expire() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.exceeded || tw.deadline.IsZero() {
+		return
+	}
+	if d := time.Until(tw.deadline); d > 0 {
+		tw.timer.Reset(d)
+		return
+	}
+
+	tw.exceeded = true
+	tw.timedOut = !tw.wroteHeader
+	tw.cancel()
+	close(tw.expired)
+}
+
+// timeoutContext is the request context of handlers with a timeout. Unlike
+// context.WithDeadline its deadline follows SetWriteDeadline.
+type timeoutContext struct {
+	context.Context
+	tw *timeoutWriter
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	c.tw.mu.Lock()
+	deadline := c.tw.deadline
+	c.tw.mu.Unlock()
+
+	if parent, ok := c.Context.Deadline(); ok && (deadline.IsZero() || parent.Before(deadline)) {
+		return parent, true
+	}
+	return deadline, !deadline.IsZero()
+}
+
+func (c *timeoutContext) Err() error {
+	c.tw.mu.Lock()
+	exceeded := c.tw.exceeded
+	c.tw.mu.Unlock()
+
+	if exceeded {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
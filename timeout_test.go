@@ -0,0 +1,131 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	router := New()
+	errs := make(chan error, 1)
+	router.Get("/slow", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		_, ok := req.Context().Deadline()
+		assert.True(t, ok)
+		<-req.Context().Done()
+		_, err := rw.Write([]byte("late"))
+		errs <- err
+	}, Timeout(10*time.Millisecond))
+	router.Get("/fast", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Header().Set("X-Fast", "1")
+		rw.Write([]byte("fast"))
+	}, Timeout(time.Second))
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+	assert.Equal(t, http.ErrHandlerTimeout, <-errs)
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "1", rw.Header().Get("X-Fast"))
+	assert.Equal(t, "fast", rw.Body.String())
+}
+
+func TestTimeoutGroup(t *testing.T) {
+	router := New()
+	router.TimeoutStatus = http.StatusGatewayTimeout
+	router.ErrorRenderer = RenderProblemJSON
+	api := router.Prefix("/api", Timeout(10*time.Millisecond))
+	api.Get("/slow", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		<-req.Context().Done()
+		assert.Equal(t, context.DeadlineExceeded, req.Context().Err())
+	})
+	api.Prefix("/api/export", Timeout(time.Second)).Get("/all", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rw.Code)
+	assert.Equal(t, "application/problem+json", rw.Header().Get("Content-Type"))
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/export/all", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestTimeoutStreaming(t *testing.T) {
+	router := New()
+	router.Get("/started", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		rw.Write([]byte("partial"))
+		<-req.Context().Done()
+	}, Timeout(10*time.Millisecond))
+	router.Get("/optout", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		assert.NoError(t, http.NewResponseController(rw).SetWriteDeadline(time.Time{}))
+		_, ok := req.Context().Deadline()
+		assert.False(t, ok)
+		time.Sleep(30 * time.Millisecond)
+		assert.NoError(t, req.Context().Err())
+		rw.Write([]byte("done"))
+	}, Timeout(10*time.Millisecond))
+	router.Get("/extend", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		deadline := time.Now().Add(time.Second)
+		assert.NoError(t, http.NewResponseController(rw).SetWriteDeadline(deadline))
+		got, _ := req.Context().Deadline()
+		assert.Equal(t, deadline, got)
+		time.Sleep(30 * time.Millisecond)
+		http.NewResponseController(rw).Flush()
+	}, Timeout(10*time.Millisecond))
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/started", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "partial", rw.Body.String())
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/optout", nil))
+	assert.Equal(t, "done", rw.Body.String())
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/extend", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.True(t, rw.Flushed)
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	router := New()
+	var got *PanicError
+	router.PanicHandler = func(rw http.ResponseWriter, req *http.Request, v any) {
+		got = v.(*PanicError)
+	}
+	router.Get("/panic", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		panic("boom")
+	}, Timeout(time.Second))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, "boom", got.Value)
+	assert.Contains(t, string(got.Stack), "timeout_test.go")
+}
+
+func TestTimeoutPanicWithoutHandler(t *testing.T) {
+	router := New()
+	router.Get("/abort", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		panic(http.ErrAbortHandler)
+	}, Timeout(time.Second))
+	router.Get("/panic", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		panic("boom")
+	}, Timeout(time.Second))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+	assert.PanicsWithValue(t, "boom", func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	})
+}
@@ -0,0 +1,127 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+)
+
+// Tracer is notified as the router serves a request, so that any tracing
+// library can be plugged in without the router depending on it.
+type Tracer interface {
+	// OnRequestStart is called before the route is resolved. The returned
+	// context, if not nil, replaces the request context, which allows the
+	// tracer to make its span available to handlers.
+	OnRequestStart(req *http.Request) context.Context
+
+	// OnMatch is called once the route is resolved, with the matched pattern
+	// and its params. The pattern is empty if no route matched.
+	OnMatch(req *http.Request, pattern string, ps Params)
+
+	// OnRequestEnd is called after the request has been served, with the
+	// status code of the response.
+	OnRequestEnd(req *http.Request, status int)
+}
+
+// SpanName returns the span name for a request with method matched by
+// pattern, such as "GET /users/:id", or method alone if pattern is empty.
+func SpanName(method, pattern string) string {
+	if pattern == "" {
+		return method
+	}
+	return method + " " + pattern
+}
+
+// TraceParentHeader is the W3C Trace Context header carrying the trace ID and
+// the parent span ID.
+const TraceParentHeader = "traceparent"
+
+// ErrInvalidTraceParent is returned by ParseTraceParent for values which
+// are not a valid traceparent header.
+var ErrInvalidTraceParent = errors.New("router: invalid traceparent")
+
+// TraceParent is the content of a W3C traceparent header.
+type TraceParent struct {
+	TraceID  [16]byte
+	ParentID [8]byte
+	Flags    byte
+}
+
+// NewTraceParent returns a sampled TraceParent with a random trace ID and
+// parent ID.
+func NewTraceParent() TraceParent {
+	var tp TraceParent
+	rand.Read(tp.TraceID[:])
+	rand.Read(tp.ParentID[:])
+	tp.Flags = 1
+	return tp
+}
+
+// ParseTraceParent parses a traceparent header value of version 00, or of a
+// later version as long as its prefix follows the version 00 format.
+func ParseTraceParent(s string) (TraceParent, error) {
+	var tp TraceParent
+	if len(s) < 55 || (len(s) > 55 && s[55] != '-') || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tp, ErrInvalidTraceParent
+	}
+
+	var version [1]byte
+	if !decodeHex(version[:], s[0:2]) || version[0] == 0xff || (version[0] == 0 && len(s) != 55) {
+		return tp, ErrInvalidTraceParent
+	}
+
+	var flags [1]byte
+	if !decodeHex(tp.TraceID[:], s[3:35]) || !decodeHex(tp.ParentID[:], s[36:52]) || !decodeHex(flags[:], s[53:55]) {
+		return tp, ErrInvalidTraceParent
+	}
+	tp.Flags = flags[0]
+
+	if tp.TraceID == ([16]byte{}) || tp.ParentID == ([8]byte{}) {
+		return tp, ErrInvalidTraceParent
+	}
+	return tp, nil
+}
+
+// decodeHex decodes the lowercase hex string s into dst.
+func decodeHex(dst []byte, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// String returns the traceparent header value, in version 00.
+func (tp TraceParent) String() string {
+	return "00-" + hex.EncodeToString(tp.TraceID[:]) + "-" +
+		hex.EncodeToString(tp.ParentID[:]) + "-" + hex.EncodeToString([]byte{tp.Flags})
+}
+
+// Sampled reports whether the sampled flag is set.
+func (tp TraceParent) Sampled() bool {
+	return tp.Flags&1 == 1
+}
+
+// Child returns a TraceParent in the same trace with a new random parent ID,
+// to be propagated by a span created under tp.
+func (tp TraceParent) Child() TraceParent {
+	rand.Read(tp.ParentID[:])
+	return tp
+}
+
+// TraceParentFromRequest returns the TraceParent carried by req, if it has a
+// valid traceparent header.
+func TraceParentFromRequest(req *http.Request) (TraceParent, bool) {
+	tp, err := ParseTraceParent(req.Header.Get(TraceParentHeader))
+	return tp, err == nil
+}
+
+// InjectTraceParent sets the traceparent header of h to tp, for outgoing
+// requests.
+func InjectTraceParent(h http.Header, tp TraceParent) {
+	h.Set(TraceParentHeader, tp.String())
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type testTracer struct {
+	name   string
+	parent TraceParent
+	params Params
+	status int
+}
+
+func (tr *testTracer) OnRequestStart(req *http.Request) context.Context {
+	tr.parent, _ = TraceParentFromRequest(req)
+	return context.WithValue(req.Context(), spanKey{}, tr)
+}
+
+func (tr *testTracer) OnMatch(req *http.Request, pattern string, ps Params) {
+	tr.name = SpanName(req.Method, pattern)
+	tr.params = ps
+}
+
+func (tr *testTracer) OnRequestEnd(req *http.Request, status int) {
+	tr.status = status
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &testTracer{}
+	router := New()
+	router.Tracer = tracer
+	router.Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		assert.Equal(t, tracer, req.Context().Value(spanKey{}))
+		assert.Equal(t, "/users/:id", MatchedPattern(req))
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	tp := NewTraceParent()
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	InjectTraceParent(req.Header, tp)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "GET /users/:id", tracer.name)
+	assert.Equal(t, Params{"id": "42"}, tracer.params)
+	assert.Equal(t, http.StatusAccepted, tracer.status)
+	assert.Equal(t, tp, tracer.parent)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/none", nil))
+	assert.Equal(t, "GET", tracer.name)
+	assert.Equal(t, http.StatusNotFound, tracer.status)
+}
+
+func TestTraceParent(t *testing.T) {
+	s := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tp, err := ParseTraceParent(s)
+	assert.NoError(t, err)
+	assert.True(t, tp.Sampled())
+	assert.Equal(t, s, tp.String())
+
+	child := tp.Child()
+	assert.Equal(t, tp.TraceID, child.TraceID)
+	assert.NotEqual(t, tp.ParentID, child.ParentID)
+
+	_, err = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	assert.NoError(t, err)
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	for _, s := range invalid {
+		_, err := ParseTraceParent(s)
+		assert.ErrorIs(t, err, ErrInvalidTraceParent, s)
+	}
+}
@@ -3,6 +3,8 @@ package router
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -17,10 +19,21 @@ type node struct {
 	wildcard       bool
 	parameterChild *node
 	children       map[string]*node
-	handlers       map[string]Handle
+	handlers       map[string][]*route
+
+	// Segment and parts of a node mixing literals and parameters, such as
+	// ":name.:ext". A node's mixed children are tried, most literal bytes
+	// first, after its static children and before its parameter child.
+	segment       string
+	parts         []segmentPart
+	mixedChildren []*node
 }
 
+// insert adds pattern to the tree and returns its node, which is an endpoint.
+// A pattern may end on a node created as an inner node of a longer pattern,
+// such as "/a" after "/a/b", which then becomes an endpoint as well.
 func (n *node) insert(pattern string) *node {
+	pattern = normalizePattern(pattern)
 	if strings.Contains(pattern, "//") {
 		panic(fmt.Errorf(`must not contain multi-slash: "%s"`, pattern))
 	}
@@ -30,20 +43,62 @@ func (n *node) insert(pattern string) *node {
 
 	p := n
 	for index, frag := range frags {
-
-		if p.children[frag] != nil {
-			p = p.children[frag]
-			continue
+		static := frag == "" || (frag[0] != ':' && frag[0] != '*' && !isMixedSegment(frag))
+		if static {
+			frag = unescapeLiteral(frag)
+			if child := p.children[frag]; child != nil {
+				// an inner node becoming an endpoint conflicts like a new one
+				if frag != "" && index == len(frags)-1 && !child.endpoint {
+					if pc := p.parameterChild; pc != nil && pc.endpoint {
+						panic("/" + pattern + " conflicts with existing pattern " + pc.pattern)
+					}
+				}
+				p = child
+				continue
+			}
 		}
 
 		nn := &node{
 			children: make(map[string]*node),
-			handlers: make(map[string]Handle),
+			handlers: make(map[string][]*route),
 		}
 
 		if frag == "" {
 			p.children[frag] = nn
-		} else if frag[0] == '*' || frag[0] == ':' {
+		} else if static {
+			if child := p.parameterChild; child != nil {
+				if child.wildcard || (index == len(frags)-1 && child.endpoint) {
+					panic("/" + pattern + " conflicts with existing pattern " + child.pattern)
+				}
+			}
+			p.children[frag] = nn
+		} else if isMixedSegment(frag) {
+			if child := p.parameterChild; child != nil && child.wildcard {
+				panic("/" + pattern + " conflicts with existing pattern " + child.pattern)
+			}
+
+			parts, err := parseSegment(frag)
+			if err != nil {
+				panic(err.Error())
+			}
+			if child := p.mixedChild(parts); child != nil {
+				if child.segment != frag {
+					panic("/" + pattern + " conflicts with existing segment " + child.segment)
+				}
+				p = child
+				continue
+			}
+
+			nn.segment, nn.parts = frag, parts
+			p.mixedChildren = append(p.mixedChildren, nn)
+			sort.SliceStable(p.mixedChildren, func(i, j int) bool {
+				a, b := p.mixedChildren[i], p.mixedChildren[j]
+				if la, lb := literalLength(a.parts), literalLength(b.parts); la != lb {
+					return la > lb
+				}
+				return a.segment < b.segment
+			})
+		} else {
 			name := frag[1:]
 			if !nameRegexp.MatchString(name) {
 				panic(fmt.Sprintf(`invalid named parameter: "%s"`, name))
@@ -51,13 +106,14 @@ func (n *node) insert(pattern string) *node {
 			nn.name = name
 
 			if frag[0] == '*' {
-				if index == len(frags)-1 {
-					for _, v := range p.children {
-						if v.pattern != "/" {
-							panic("/" + pattern + " conflicts with existing pattern " + v.pattern)
-						}
+				for _, v := range p.children {
+					if v.pattern != "/" {
+						panic("/" + pattern + " conflicts with existing pattern " + v.pattern)
 					}
 				}
+				if len(p.mixedChildren) > 0 {
+					panic("/" + pattern + " conflicts with existing segment " + p.mixedChildren[0].segment)
+				}
 				nn.wildcard = true
 			}
 
@@ -80,36 +136,85 @@ func (n *node) insert(pattern string) *node {
 			} else {
 				p.parameterChild = nn
 			}
-		} else {
-			if child := p.parameterChild; child != nil {
-				if child.wildcard || (index == len(frags)-1 && child.endpoint) {
-					panic("/" + pattern + " conflicts with existing pattern " + child.pattern)
-				}
-			}
-			p.children[frag] = nn
 		}
 
 		p = nn
-		if index == len(frags)-1 {
-			nn.endpoint = true
+	}
+
+	p.endpoint = true
+	p.pattern = "/" + pattern
+	return p
+}
+
+// mixedChild returns the mixed child of n with the shape of parts.
+func (n *node) mixedChild(parts []segmentPart) *node {
+	shape := segmentShape(parts)
+	for _, child := range n.mixedChildren {
+		if segmentShape(child.parts) == shape {
+			return child
+		}
+	}
+	return nil
+}
+
+// matchMixed returns the first mixed child of n matching the path segment
+// frag, and the values of its parameters. Only endpoints are considered for
+// the last segment of a path, tsr reports whether a non-endpoint matched
+// with a trailing slash child.
+func (n *node) matchMixed(frag string, last bool) (child *node, values []string, tsr bool) {
+	for _, c := range n.mixedChildren {
+		v, ok := matchSegment(c.parts, frag, nil)
+		if !ok {
 			continue
 		}
+		if last && c.children[""] != nil {
+			tsr = true
+		}
+		if last && !c.endpoint {
+			continue
+		}
+		return c, v, tsr
+	}
+	return nil, nil, tsr
+}
 
-		if nn.wildcard {
-			panic(fmt.Sprintf("can't define path after wildcard pattern, %s", pattern))
+func (n *node) addHandle(method string, rt *route) {
+	// a route without matchers nor produced media types accepts every
+	// request, so it is kept last as the fallback of the others, and there
+	// can be only one.
+	routes := n.handlers[method]
+	if last := len(routes) - 1; last >= 0 && routes[last].unguarded() {
+		if rt.unguarded() {
+			panic(n.pattern + ", method: " + method + " handler already exist!")
 		}
+		n.handlers[method] = slices.Insert(routes, last, rt)
+		return
 	}
 
-	p.pattern = "/" + pattern
-	return p
+	n.handlers[method] = append(routes, rt)
 }
 
-func (n *node) addHandle(method string, handler Handle) {
-	if n.handlers[method] != nil {
-		panic("/" + n.pattern + ", method: " + method + " handler already exist!")
+// walk calls fn for n and all its descendants, static children in lexical
+// order, then mixed children, before the parameter child.
+func (n *node) walk(fn func(*node)) {
+	fn(n)
+
+	keys := make([]string, 0, len(n.children))
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		n.children[k].walk(fn)
 	}
 
-	n.handlers[method] = handler
+	for _, child := range n.mixedChildren {
+		child.walk(fn)
+	}
+
+	if n.parameterChild != nil {
+		n.parameterChild.walk(fn)
+	}
 }
 
 func (n *node) find(path string) (*node, Params, bool) {
@@ -117,11 +222,14 @@ func (n *node) find(path string) (*node, Params, bool) {
 		panic(fmt.Errorf(`path must start with "/": "%s"`, path))
 	}
 
-	var tsr bool
-	var matchedParams map[string]string
 	path = strings.TrimPrefix(path, "/")
-	frags := strings.Split(path, "/")
+	return n.match(strings.Split(path, "/"), nil)
+}
 
+// match resolves the path segments frags from n, adding the parameters to
+// matchedParams.
+func (n *node) match(frags []string, matchedParams Params) (*node, Params, bool) {
+	var tsr bool
 	p := n
 	for index, frag := range frags {
 		nn := p.children[frag]
@@ -134,6 +242,13 @@ func (n *node) find(path string) (*node, Params, bool) {
 			nn = nil
 		}
 
+		var values []string
+		if nn == nil && len(p.mixedChildren) > 0 {
+			var mixedTSR bool
+			nn, values, mixedTSR = p.matchMixed(frag, index == len(frags)-1)
+			tsr = tsr || mixedTSR
+		}
+
 		if nn == nil {
 			nn = p.parameterChild
 		}
@@ -147,14 +262,25 @@ func (n *node) find(path string) (*node, Params, bool) {
 		}
 
 		p = nn
-		if p.name != "" {
+		if p.parts != nil {
+			if matchedParams == nil {
+				matchedParams = make(map[string]string)
+			}
+
+			i := 0
+			for _, part := range p.parts {
+				if part.param != "" {
+					matchedParams[part.param] = values[i]
+					i++
+				}
+			}
+		} else if p.name != "" {
 			if matchedParams == nil {
 				matchedParams = make(map[string]string)
 			}
 
 			if p.wildcard {
-				matchedParams[p.name] = strings.Join(frags[index:], "/")
-				break
+				return p.matchWildcard(frags[index:], matchedParams)
 			} else {
 				matchedParams[p.name] = frag
 			}
@@ -168,3 +294,45 @@ func (n *node) find(path string) (*node, Params, bool) {
 
 	return p, matchedParams, tsr
 }
+
+// matchWildcard resolves the path segments frags from the wildcard node n. A
+// wildcard followed by more segments matches the shortest span of at least
+// one segment which lets the rest of the path match, backtracking over longer
+// spans. If none does and n is an endpoint, n matches all of frags. Otherwise
+// tsr reports whether some span matched with a trailing slash added or
+// removed.
+//
+// When only static segments follow the wildcard, spans are skipped unless
+// the next segment is one of them. Otherwise each wildcard may try every
+// span, so matching costs up to the number of segments to the power of the
+// number of wildcards; Router.MaxSegments bounds it.
+func (n *node) matchWildcard(frags []string, matchedParams Params) (*node, Params, bool) {
+	var tsr bool
+	if len(n.children) > 0 || len(n.mixedChildren) > 0 || n.parameterChild != nil {
+		static := len(n.mixedChildren) == 0 && n.parameterChild == nil
+		for span := 1; span < len(frags); span++ {
+			if static && n.children[frags[span]] == nil {
+				continue
+			}
+
+			m, ps, spanTSR := n.match(frags[span:], nil)
+			tsr = tsr || spanTSR
+			if m == nil || !m.endpoint {
+				continue
+			}
+
+			matchedParams[n.name] = strings.Join(frags[:span], "/")
+			for k, v := range ps {
+				matchedParams[k] = v
+			}
+			return m, matchedParams, false
+		}
+	}
+
+	if !n.endpoint {
+		return nil, matchedParams, tsr
+	}
+
+	matchedParams[n.name] = strings.Join(frags, "/")
+	return n, matchedParams, false
+}
@@ -3,6 +3,8 @@ package router
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -157,4 +159,194 @@ func TestFind(t *testing.T) {
 		assert.Equal(t, matched, n, "same pattern, should return same tree node")
 		assert.Equal(t, ps["b"], "name/cssivision")
 	})
+	t.Run("test for mixed segments", func(t *testing.T) {
+		tree := New().tree
+
+		file := tree.insert("/files/:name.:ext")
+		year := tree.insert("/files/report-:year.csv")
+		static := tree.insert("/files/index.html")
+		major := tree.insert("/v:major/users")
+
+		matched, ps, _ := tree.find("/files/archive.tar.gz")
+		assert.Equal(t, file, matched)
+		assert.Equal(t, Params{"name": "archive.tar", "ext": "gz"}, ps)
+
+		matched, ps, _ = tree.find("/files/report-2024.csv")
+		assert.Equal(t, year, matched)
+		assert.Equal(t, Params{"year": "2024"}, ps)
+
+		matched, _, _ = tree.find("/files/index.html")
+		assert.Equal(t, static, matched)
+
+		matched, _, _ = tree.find("/files/readme")
+		assert.Nil(t, matched)
+
+		params := New().tree
+		mixed := params.insert("/files/:name.:ext")
+		param := params.insert("/files/:id")
+
+		matched, _, _ = params.find("/files/a.txt")
+		assert.Equal(t, mixed, matched)
+
+		matched, ps, _ = params.find("/files/.gitignore")
+		assert.Equal(t, param, matched)
+		assert.Equal(t, Params{"id": ".gitignore"}, ps)
+
+		matched, ps, _ = tree.find("/v2/users")
+		assert.Equal(t, major, matched)
+		assert.Equal(t, Params{"major": "2"}, ps)
+
+		matched, _, tsr := tree.find("/v2/users/")
+		assert.Nil(t, matched)
+		assert.True(t, tsr)
+
+		assert.Equal(t, file, tree.insert("/files/:name.:ext"))
+		assert.Panics(t, func() {
+			tree.insert("/files/:base.:suffix")
+		})
+		assert.Panics(t, func() {
+			tree.insert("/files/:a:b")
+		})
+		assert.Panics(t, func() {
+			tree.insert("/files/*path")
+		})
+		assert.Panics(t, func() {
+			tree := New().tree
+			tree.insert("/static/*path")
+			tree.insert("/static/:name.css")
+		})
+	})
+}
+
+func TestInsertInnerEndpoint(t *testing.T) {
+	tree := New().tree
+	leaf := tree.insert("/a/b")
+	p, _, tsr := tree.find("/a")
+	assert.Nil(t, p)
+	assert.False(t, tsr)
+
+	// a pattern ending on an existing inner node makes it an endpoint
+	inner := tree.insert("/a")
+	assert.True(t, inner.endpoint)
+	p, _, _ = tree.find("/a")
+	assert.Equal(t, inner, p)
+	p, _, _ = tree.find("/a/b")
+	assert.Equal(t, leaf, p)
+
+	params := New().tree
+	params.insert("/users/:id/posts")
+	user := params.insert("/users/:id")
+	p, ps, _ := params.find("/users/42")
+	assert.Equal(t, user, p)
+	assert.Equal(t, Params{"id": "42"}, ps)
+
+	// as a new endpoint would, it conflicts with a parameter endpoint
+	params.insert("/users/me/posts")
+	assert.Panics(t, func() {
+		params.insert("/users/me")
+	})
+}
+
+func TestMidPatternWildcard(t *testing.T) {
+	tree := New().tree
+	blob := tree.insert("/repos/*path/blob/:ref")
+	tree_ := tree.insert("/repos/*path/tree/:ref/*file")
+	all := tree.insert("/repos/*path")
+
+	matched, ps, _ := tree.find("/repos/a/b/blob/main")
+	assert.Equal(t, blob, matched)
+	assert.Equal(t, Params{"path": "a/b", "ref": "main"}, ps)
+
+	// the shortest span wins
+	matched, ps, _ = tree.find("/repos/a/blob/b/blob/main")
+	assert.Equal(t, blob, matched)
+	assert.Equal(t, Params{"path": "a/blob/b", "ref": "main"}, ps)
+
+	matched, ps, _ = tree.find("/repos/a/tree/v1/docs/readme.md")
+	assert.Equal(t, tree_, matched)
+	assert.Equal(t, Params{"path": "a", "ref": "v1", "file": "docs/readme.md"}, ps)
+
+	// backtracks to the terminal wildcard when the rest cannot match
+	matched, ps, _ = tree.find("/repos/a/blob")
+	assert.Equal(t, all, matched)
+	assert.Equal(t, Params{"path": "a/blob"}, ps)
+
+	matched, ps, _ = tree.find("/repos/blob/main")
+	assert.Equal(t, all, matched)
+	assert.Equal(t, Params{"path": "blob/main"}, ps)
+
+	inner := New().tree
+	inner.insert("/a/*path/b")
+	matched, _, _ = inner.find("/a/x/c")
+	assert.Nil(t, matched)
+	matched, _, _ = inner.find("/a/b")
+	assert.Nil(t, matched)
+
+	// a trailing slash added or removed redirects
+	matched, _, tsr := inner.find("/a/x/b/")
+	assert.Nil(t, matched)
+	assert.True(t, tsr)
+	slash := New().tree
+	slash.insert("/a/*path/b/")
+	matched, _, tsr = slash.find("/a/x/b")
+	assert.Nil(t, matched)
+	assert.True(t, tsr)
+	matched, _, tsr = inner.find("/a/x/c/")
+	assert.Nil(t, matched)
+	assert.False(t, tsr)
+
+	router := New()
+	router.Get("/repos/*path/tree", func(rw http.ResponseWriter, req *http.Request, _ Params) {})
+	res := router.Lookup(http.MethodGet, "/repos/a/tree/")
+	assert.Equal(t, LookupRedirect, res.Kind)
+	assert.Equal(t, "/repos/a/tree", res.Location)
+
+	// spans are only tried where the next static segment is found
+	twice := New().tree
+	leaf := twice.insert("/r/*a/x/*b/y/:c")
+	frags := make([]string, 20000)
+	for i := range frags {
+		frags[i] = "z"
+	}
+	matched, _, _ = twice.find("/r/" + strings.Join(frags, "/"))
+	assert.Nil(t, matched)
+	frags[100], frags[200] = "x", "y"
+	matched, ps, _ = twice.find("/r/" + strings.Join(frags[:202], "/"))
+	assert.Equal(t, leaf, matched)
+	assert.Equal(t, "z", ps["c"])
+
+	assert.Panics(t, func() {
+		tree.insert("/repos/*name/blob")
+	})
+	assert.Panics(t, func() {
+		tree.insert("/repos/new")
+	})
+	assert.Panics(t, func() {
+		tree := New().tree
+		tree.insert("/repos/new/x")
+		tree.insert("/repos/*path/blob")
+	})
+}
+
+func TestEscapedLiterals(t *testing.T) {
+	tree := New().tree
+	literal := tree.insert(`/a/\:id`)
+	param := tree.insert("/a/:id/x")
+	star := tree.insert(`/b/\*`)
+	mixed := tree.insert(`/c/:name\:v:version`)
+
+	matched, ps, _ := tree.find("/a/:id")
+	assert.Equal(t, literal, matched)
+	assert.Nil(t, ps)
+
+	matched, ps, _ = tree.find("/a/42/x")
+	assert.Equal(t, param, matched)
+	assert.Equal(t, Params{"id": "42"}, ps)
+
+	matched, _, _ = tree.find("/b/*")
+	assert.Equal(t, star, matched)
+
+	matched, ps, _ = tree.find("/c/app:v2")
+	assert.Equal(t, mixed, matched)
+	assert.Equal(t, Params{"name": "app", "version": "2"}, ps)
 }
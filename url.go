@@ -0,0 +1,121 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ExpandPattern builds the path matched by pattern with the params ps. Named
+// parameter values are escaped, wildcard values keep their slashes. The
+// pattern may use the syntax of http.ServeMux. Optional parts are kept only
+// when ps has values for all their parameters.
+func ExpandPattern(pattern string, ps Params) (string, error) {
+	pattern, err := convertPattern(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	patterns, err := expandOptional(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var path string
+	for _, p := range patterns {
+		if path, err = expandPattern(p, ps); err == nil {
+			break
+		}
+	}
+	return path, err
+}
+
+// expandPattern is ExpandPattern for a pattern without optional parts.
+func expandPattern(pattern string, ps Params) (string, error) {
+	param := func(name string) (string, error) {
+		value, ok := ps[name]
+		if !ok {
+			return "", fmt.Errorf(`missing parameter "%s" for pattern "%s"`, name, pattern)
+		}
+		if value == "" {
+			return "", fmt.Errorf(`empty parameter "%s" for pattern "%s"`, name, pattern)
+		}
+		return value, nil
+	}
+
+	frags := strings.Split(pattern, "/")
+	for i, frag := range frags {
+		if isMixedSegment(frag) {
+			parts, err := parseSegment(frag)
+			if err != nil {
+				return "", err
+			}
+
+			var sb strings.Builder
+			for _, part := range parts {
+				if part.param == "" {
+					sb.WriteString(part.literal)
+					continue
+				}
+				value, err := param(part.param)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(url.PathEscape(value))
+			}
+			frags[i] = sb.String()
+			continue
+		}
+
+		if frag == "" || (frag[0] != ':' && frag[0] != '*') {
+			frags[i] = unescapeLiteral(frag)
+			continue
+		}
+
+		name := frag[1:]
+		if frag[0] == ':' {
+			value, err := param(name)
+			if err != nil {
+				return "", err
+			}
+			frags[i] = url.PathEscape(value)
+			continue
+		}
+
+		value, ok := ps[name]
+		if !ok {
+			return "", fmt.Errorf(`missing parameter "%s" for pattern "%s"`, name, pattern)
+		}
+		if value == "" && i < len(frags)-1 {
+			return "", fmt.Errorf(`empty parameter "%s" for pattern "%s"`, name, pattern)
+		}
+
+		segments := strings.Split(value, "/")
+		for j, s := range segments {
+			segments[j] = url.PathEscape(s)
+		}
+		frags[i] = strings.Join(segments, "/")
+	}
+
+	return strings.Join(frags, "/"), nil
+}
+
+// Path builds the path of the route registered with Name(name), filled with
+// the params ps.
+func (r *Router) Path(name string, ps Params) (string, error) {
+	rt, ok := r.Route(name)
+	if !ok {
+		return "", fmt.Errorf(`no route named "%s"`, name)
+	}
+	return ExpandPattern(rt.Pattern, ps)
+}
+
+// Route returns the first route registered with Name(name).
+func (r *Router) Route(name string) (Route, bool) {
+	for _, rt := range r.Routes() {
+		if rt.Name == name {
+			return rt, true
+		}
+	}
+	return Route{}, false
+}
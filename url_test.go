@@ -0,0 +1,44 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandPattern(t *testing.T) {
+	path, err := ExpandPattern("/users/:id/files/*path", Params{"id": "a b", "path": "x/y z"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/a%20b/files/x/y%20z", path)
+
+	path, err = ExpandPattern("/files/{name}.{ext}/v{major}", Params{"name": "a.tar", "ext": "gz", "major": "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/files/a.tar.gz/v2", path)
+
+	_, err = ExpandPattern("/files/:name.:ext", Params{"name": "a"})
+	assert.Error(t, err)
+	path, err = ExpandPattern("/repos/*path/blob/:ref", Params{"path": "a/b", "ref": "main"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/repos/a/b/blob/main", path)
+	_, err = ExpandPattern("/repos/*path/blob/:ref", Params{"path": "", "ref": "main"})
+	assert.Error(t, err)
+
+	_, err = ExpandPattern("/users/:id", Params{})
+	assert.Error(t, err)
+	_, err = ExpandPattern("/users/:id", Params{"id": ""})
+	assert.Error(t, err)
+}
+
+func TestPath(t *testing.T) {
+	router := New()
+	router.Prefix("/api").Get("/users/:id", func(rw http.ResponseWriter, req *http.Request, _ Params) {}, Name("user"))
+
+	path, err := router.Path("user", Params{"id": "42"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/users/42", path)
+	assert.Equal(t, LookupFound, router.Lookup(http.MethodGet, path).Kind)
+
+	_, err = router.Path("none", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,263 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versioning maps API versions to route groups. Routes of version "2" are
+// registered on the group returned by Version("2") and live under
+// <prefix>/v2. A request names the version it wants in its path, in a header
+// or in a vendor media type of its Accept header:
+//
+//	/api/v2/users
+//	/api/users with Accept-Version: 2
+//	/api/users with Accept: application/vnd.acme.v2+json
+//
+// A version which doesn't define a route falls back to the newest older
+// version which does.
+type Versioning struct {
+	// Header naming the requested version, "Accept-Version" if empty.
+	Header string
+
+	// Vendor of the media types naming the requested version, such as
+	// "acme" for application/vnd.acme.v2+json. Media types are not consulted
+	// if it is empty.
+	Vendor string
+
+	// Version used when the request names none, the newest one if empty.
+	Default string
+
+	router   *Router
+	prefix   string
+	versions []*Version
+}
+
+// Version is an API version of a Versioning, and the group its routes are
+// registered on.
+type Version struct {
+	RouterPrefix
+
+	Name string
+
+	// Date from which the version is deprecated, sent in a Deprecation
+	// header to the requests for it if not zero.
+	Deprecated time.Time
+
+	// Date after which the version will stop being served, sent in a Sunset
+	// header to the requests for it if not zero.
+	Sunset time.Time
+
+	parts []int
+}
+
+// Versioning returns a Versioning for the versions of the API under prefix.
+func (r *Router) Versioning(prefix string) *Versioning {
+	if prefix == "" || prefix[0] != '/' {
+		panic("prefix must begin with '/', '" + prefix + "'")
+	}
+
+	vs := &Versioning{
+		router: r,
+		prefix: strings.TrimSuffix(prefix, "/"),
+	}
+	r.versionings = append(r.versionings, vs)
+	return vs
+}
+
+// Version returns the group of the API version name, such as "1" or "2.1",
+// creating it if needed. Its routes are registered under <prefix>/v<name>.
+func (vs *Versioning) Version(name string, opts ...RouteOption) *Version {
+	for _, v := range vs.versions {
+		if v.Name == name {
+			return v
+		}
+	}
+
+	parts, ok := parseVersion(name)
+	if !ok {
+		panic("invalid version: '" + name + "'")
+	}
+
+	v := &Version{
+		RouterPrefix: *vs.router.Prefix(vs.prefix+"/v"+name, opts...),
+		Name:         name,
+		parts:        parts,
+	}
+	vs.versions = append(vs.versions, v)
+	sort.Slice(vs.versions, func(i, j int) bool {
+		return compareVersions(vs.versions[i].parts, vs.versions[j].parts) < 0
+	})
+	return v
+}
+
+// Routes returns the routes served for version name, which includes the
+// routes it falls back to in older versions.
+func (vs *Versioning) Routes(name string) []Route {
+	requested, ok := parseVersion(name)
+	if !ok {
+		return nil
+	}
+
+	type key struct{ method, pattern string }
+	seen := make(map[key]bool)
+	var routes []Route
+	for i := len(vs.versions) - 1; i >= 0; i-- {
+		v := vs.versions[i]
+		if compareVersions(v.parts, requested) > 0 {
+			continue
+		}
+
+		for _, rt := range vs.router.Routes() {
+			rel, ok := strings.CutPrefix(rt.Pattern, v.basePath)
+			if !ok || (rel != "" && rel[0] != '/') {
+				continue
+			}
+			if k := (key{rt.Method, rel}); !seen[k] {
+				seen[k] = true
+				routes = append(routes, rt)
+			}
+		}
+	}
+
+	return routes
+}
+
+// resolve returns the name of the version requested by req for path, its
+// Version if it is registered, and the paths to look up for it, from the
+// requested version down to the oldest one. It returns no paths if path is
+// not under the prefix of vs.
+func (vs *Versioning) resolve(req *http.Request, path string) (string, *Version, []string) {
+	if len(vs.versions) == 0 {
+		return "", nil, nil
+	}
+
+	cmp, prefix := path, vs.prefix
+	if vs.router.IgnoreCase {
+		cmp, prefix = strings.ToLower(cmp), strings.ToLower(prefix)
+	}
+	if !strings.HasPrefix(cmp, prefix) || (len(cmp) > len(prefix) && cmp[len(prefix)] != '/') {
+		return "", nil, nil
+	}
+	rest := path[len(vs.prefix):]
+
+	var requested []int
+	seg, after, found := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	if len(seg) > 1 && (seg[0] == 'v' || seg[0] == 'V') {
+		if parts, ok := parseVersion(seg[1:]); ok {
+			requested = parts
+			rest = ""
+			if found {
+				rest = "/" + after
+			}
+		}
+	}
+	if requested == nil {
+		requested = vs.requested(req)
+	}
+
+	var target *Version
+	var paths []string
+	for i := len(vs.versions) - 1; i >= 0; i-- {
+		v := vs.versions[i]
+		c := compareVersions(v.parts, requested)
+		if c > 0 {
+			continue
+		}
+		if c == 0 {
+			target = v
+		}
+		paths = append(paths, v.basePath+rest)
+	}
+	return formatVersion(requested), target, paths
+}
+
+// requested returns the version named by the headers of req, or the default
+// one.
+func (vs *Versioning) requested(req *http.Request) []int {
+	header := vs.Header
+	if header == "" {
+		header = "Accept-Version"
+	}
+	if parts, ok := parseVersion(strings.TrimPrefix(strings.TrimSpace(req.Header.Get(header)), "v")); ok {
+		return parts
+	}
+
+	if vs.Vendor != "" {
+		prefix := "application/vnd." + strings.ToLower(vs.Vendor) + ".v"
+		for _, r := range strings.Split(req.Header.Get("Accept"), ",") {
+			mt, _, err := mime.ParseMediaType(strings.TrimSpace(r))
+			if err != nil || !strings.HasPrefix(mt, prefix) {
+				continue
+			}
+			v, _, _ := strings.Cut(mt[len(prefix):], "+")
+			if parts, ok := parseVersion(v); ok {
+				return parts
+			}
+		}
+	}
+
+	if parts, ok := parseVersion(vs.Default); ok {
+		return parts
+	}
+	return vs.versions[len(vs.versions)-1].parts
+}
+
+// writeHeaders sets the Deprecation and Sunset headers of v.
+func (v *Version) writeHeaders(h http.Header) {
+	if !v.Deprecated.IsZero() {
+		h.Set("Deprecation", "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+	}
+	if !v.Sunset.IsZero() {
+		h.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseVersion parses versions such as "2" or "2.1".
+func parseVersion(s string) ([]int, bool) {
+	if s == "" {
+		return nil, false
+	}
+
+	var parts []int
+	for _, p := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || p[0] == '+' {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+func formatVersion(parts []int) string {
+	s := make([]string, len(parts))
+	for i, p := range parts {
+		s[i] = strconv.Itoa(p)
+	}
+	return strings.Join(s, ".")
+}
+
+// compareVersions compares a and b part by part, missing parts counting as 0.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioning(t *testing.T) {
+	router := New()
+	api := router.Versioning("/api")
+	api.Vendor = "acme"
+
+	reply := func(body string) Handle {
+		return func(rw http.ResponseWriter, req *http.Request, _ Params) {
+			rw.Write([]byte(body))
+		}
+	}
+	v1 := api.Version("1")
+	v1.Deprecated = time.Unix(1688169599, 0)
+	v1.Sunset = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	v1.Get("/users/:id", reply("v1 user"))
+	v1.Get("/orders", reply("v1 orders"))
+	v2 := api.Version("2")
+	v2.Get("/users/:id", reply("v2 user"))
+	assert.Equal(t, v1, api.Version("1"))
+
+	cases := []struct {
+		target, header, value string
+		body                  string
+	}{
+		{"/api/v1/users/1", "", "", "v1 user"},
+		{"/api/v2/users/1", "", "", "v2 user"},
+		{"/api/v2/orders", "", "", "v1 orders"},
+		{"/api/users/1", "", "", "v2 user"},
+		{"/api/users/1", "Accept-Version", "1", "v1 user"},
+		{"/api/users/1", "Accept", "application/vnd.acme.v1+json", "v1 user"},
+		{"/api/users/1", "Accept-Version", "7", "v2 user"},
+		{"/api/orders", "Accept-Version", "2", "v1 orders"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.target, nil)
+		if c.header != "" {
+			req.Header.Set(c.header, c.value)
+		}
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		assert.Equal(t, http.StatusOK, rw.Code, c.target)
+		assert.Equal(t, c.body, rw.Body.String(), c.target)
+	}
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+	assert.Equal(t, "@1688169599", rw.Header().Get("Deprecation"))
+	assert.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", rw.Header().Get("Sunset"))
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/v2/orders", nil))
+	assert.Equal(t, "", rw.Header().Get("Deprecation"))
+
+	res := router.Lookup(http.MethodGet, "/api/v2/orders")
+	assert.Equal(t, LookupFound, res.Kind)
+	assert.Equal(t, "/api/v1/orders", res.Pattern)
+	assert.Equal(t, "2", res.Version)
+
+	assert.Equal(t, LookupNotFound, router.Lookup(http.MethodGet, "/api/v2/none").Kind)
+	assert.Equal(t, LookupNotFound, router.Lookup(http.MethodGet, "/apix/users/1").Kind)
+
+	var patterns []string
+	for _, rt := range api.Routes("2") {
+		patterns = append(patterns, rt.Pattern)
+	}
+	assert.Equal(t, []string{"/api/v2/users/:id", "/api/v1/orders"}, patterns)
+	assert.Len(t, api.Routes("1"), 2)
+	assert.Empty(t, api.Routes("0"))
+}
+
+func TestCompareVersions(t *testing.T) {
+	parse := func(s string) []int {
+		parts, ok := parseVersion(s)
+		assert.True(t, ok, s)
+		return parts
+	}
+	assert.Equal(t, -1, compareVersions(parse("1"), parse("1.1")))
+	assert.Equal(t, 0, compareVersions(parse("2"), parse("2.0")))
+	assert.Equal(t, 1, compareVersions(parse("10"), parse("9.9")))
+
+	for _, s := range []string{"", "x", "1.", "+1", "-1"} {
+		_, ok := parseVersion(s)
+		assert.False(t, ok, s)
+	}
+}